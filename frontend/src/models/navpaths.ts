@@ -11,6 +11,9 @@ export type ClusterQuery = { k8sCtx: string, k8sNs: string }
 
 export type ResourcesQuery = ClusterQuery & {
     query: string
+    sortBy?: string // column name, prefixed with - for descending
+    wide?: string // "true" for the columns kubectl shows with -o wide
+    filter?: string // row filter expression, e.g. Status != Running && Restarts > 3
 }
 export const pathResources = (params: ResourcesQuery) =>
     `/resources${toQueryString(params)}`
@@ -11,6 +11,7 @@ export type KubeResource = {
     describe: string;
     yaml: string;
     object: { [key: string]: any };
+    owners: { [path: string]: kube.FieldOwner[] };
     errors: any[];
     references: KubeReference[]
 }
@@ -54,6 +55,7 @@ export type TableRow = {
     isVisible: boolean
     rowIdx?: number // index among all RenderTables on the page
     name: string
+    namespace?: string
 }
 export type RenderTable = {
     kind: string,
@@ -68,7 +70,14 @@ export type RenderTable = {
 
 export const fetchK8sResourceTable = (query: () => ResourcesQuery | undefined) => {
     const fetchResources = (source: ResourcesQuery) => {
-        return KubeResourceList(source.k8sCtx, source.k8sNs || "", source.query)
+        const sortBy = source.sortBy || ""
+        const opts = kube.QueryOptions.createFrom({
+            sortBy: _.trimStart(sortBy, "-"),
+            descending: sortBy.startsWith("-"),
+            wide: source.wide == "true",
+            filter: source.filter || "",
+        })
+        return KubeResourceList(source.k8sCtx, source.k8sNs || "", source.query, opts)
             .then(resourceTables => {
                 const renderTables = []
                 let rowNum = 0
@@ -113,7 +122,9 @@ const buildRenderTable = (resourceTable: kube.ResourceTable): RenderTable => {
     const rows = new Array<TableRow>()
     const rowCount = table.rows.length
     for (let i = 0; i < rowCount; i++) {
-        const name = resourceTable.tableRowNames[i]
+        // each row carries the object's metadata
+        const metadata = table.rows[i].object?.metadata
+        const name = metadata?.name || resourceTable.tableRowNames[i]
         const values = table.rows[i].cells.map(c => { return { value: c, isName: false } })
         // anecdotally the first column is always the name, but even if not make sure the value works
         // in the link
@@ -123,6 +134,7 @@ const buildRenderTable = (resourceTable: kube.ResourceTable): RenderTable => {
             cells: values,
             isVisible: true,
             name: name,
+            namespace: metadata?.namespace,
         }
     }
 
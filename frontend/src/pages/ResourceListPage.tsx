@@ -74,7 +74,7 @@ export const ResourceList: Component<ResourceListProps> = (props) => {
         if (foundTable && foundRow) {
             const params: ResourceQuery = {
                 k8sCtx: props.k8sCtx,
-                k8sNs: props.k8sNs,
+                k8sNs: foundRow.namespace || props.k8sNs,
                 group: foundTable.group || "",
                 kind: foundTable.kind,
                 name: foundRow.name,
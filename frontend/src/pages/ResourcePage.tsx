@@ -1,12 +1,13 @@
 import type { Component } from "solid-js";
-import { createEffect, createSignal, Show, For, Switch, Match, onMount, onCleanup } from "solid-js"
+import { createEffect, createResource, createSignal, Show, For, Switch, Match, onMount, onCleanup } from "solid-js"
 import { useSearchParams, useLocation } from "@solidjs/router";
 import { pathResource, ResourceQuery } from '../models/navpaths';
 import { setPageTitle } from '../models/pageMeta';
 import { BreadcrumbBuilder, setBreadcrumbs } from '../models/breadcrumbs';
 import { fetchK8sResource, KubeReference } from "../models/resourceData";
 import { FindText } from "../components/FindFilter";
-import { relations } from "../../wailsjs/go/models";
+import { kube, relations } from "../../wailsjs/go/models";
+import { RenderResource } from "../../wailsjs/go/desktop/FrontendApi";
 import styles from './ResourcePage.module.css';
 import _ from "lodash";
 
@@ -60,6 +61,13 @@ export const ResourcePage: Component = () => {
     const nsTabs = [newYamlTab, describeTab, yamlTab]
     const [selectedTab, setSelectedTab] = createSignal(newYamlTab)
 
+    // Modes of kube.RenderObject
+    const renderModes = ['full', 'clean', 'spec', 'json']
+    const [renderMode, setRenderMode] = createSignal('full')
+    const [rendered] = createResource(
+        () => resource.state == 'ready' && renderMode() != 'full' ? { object: resource().object, mode: renderMode() } : false,
+        ({ object, mode }) => RenderResource(object, mode))
+
     return (
         <div>
             <FindText />
@@ -78,8 +86,8 @@ export const ResourcePage: Component = () => {
                     <For each={resource().references}>
                         {(rel: relations.Reference, i) =>
                             <span>
-                                <a href={relationPath(rel)}>
-                                    {rel.Kind.toLowerCase()}
+                                <a href={relationPath(rel)} title={rel.Inferred ? 'inferred' : undefined}>
+                                    {rel.Kind.toLowerCase()}{rel.Inferred ? '?' : ''}
                                 </a>
                                 <Show when={i() != resource().references.length - 1}>
                                     ,
@@ -104,7 +112,7 @@ export const ResourcePage: Component = () => {
 
                 <Show when={selectedTab() == newYamlTab && resource().object}>
                     <div class={styles.mainContent}>
-                        <YamlFixer value={resource().object} references={referenceMap(resource().references)} />
+                        <YamlFixer value={resource().object} references={referenceMap(resource().references)} owners={resource().owners} />
                     </div>
                 </Show>
 
@@ -113,7 +121,14 @@ export const ResourcePage: Component = () => {
                 </Show>
 
                 <Show when={selectedTab() == yamlTab}>
-                    <pre class={styles.mainContent}>{resource().yaml}</pre>
+                    <div class="buttons has-addons">
+                        <For each={renderModes}>
+                            {(m) =>
+                                <button class="button is-small" classList={{ "is-selected": m == renderMode() }} onclick={() => setRenderMode(m)}>{m}</button>
+                            }
+                        </For>
+                    </div>
+                    <pre class={styles.mainContent}>{renderMode() == 'full' ? resource().yaml : rendered()}</pre>
                 </Show>
             </Show>
         </div>
@@ -126,6 +141,8 @@ type YamlProps = {
     path?: string
     freezePath?: string
     references: Map<string, KubeReference>
+    // FieldOwners by path
+    owners?: { [path: string]: kube.FieldOwner[] }
 }
 
 const YamlFixer: Component<YamlProps> = (props: YamlProps) => {
@@ -339,7 +356,7 @@ const Yaml: Component<YamlProps> = (props: YamlProps) => {
             </Match>
             <Match when={typeof (props.value) === "string"}>
                 <span class={styles.yamlValue}>
-                    <YamlString value={props.value} path={path} indent={indent + 1} references={props.references} />
+                    <YamlString value={props.value} path={path} indent={indent + 1} references={props.references} owners={props.owners} />
                 </span>
             </Match>
             <Match when={Array.isArray(props.value)}>
@@ -353,7 +370,7 @@ const Yaml: Component<YamlProps> = (props: YamlProps) => {
                                 <ol style={indentStyle(indent)} class={styles.yamlArray}>
                                     <li>
                                         <div class={styles.yamlArrayEntry} data-freeze-yaml={freezePathAddArray(freezePath, v)}>
-                                            <Yaml value={v} indent={0} path={pathAddArray(path, idx())} freezePath={freezePathAddArray(freezePath, v)} references={props.references} />
+                                            <Yaml value={v} indent={0} path={pathAddArray(path, idx())} freezePath={freezePathAddArray(freezePath, v)} references={props.references} owners={props.owners} />
                                         </div>
                                     </li>
                                 </ol>
@@ -366,10 +383,10 @@ const Yaml: Component<YamlProps> = (props: YamlProps) => {
                 <For each={itrKeys()}>
                     {key =>
                         <div data-freeze-yaml={freezePathAdd(freezePath, key)}>
-                            <span style={indentStyle(indent)} class={styles.yamlKey}>
+                            <span style={indentStyle(indent)} class={styles.yamlKey} title={ownersTitle(props.owners, pathAdd(path, key))}>
                                 {key}:&nbsp;
                             </span>
-                            <Yaml value={props.value[key]} indent={indent + 1} path={pathAdd(path, key)} freezePath={freezePathAdd(freezePath, key)} references={props.references} />
+                            <Yaml value={props.value[key]} indent={indent + 1} path={pathAdd(path, key)} freezePath={freezePathAdd(freezePath, key)} references={props.references} owners={props.owners} />
                         </div>}
                 </For>
                 <Show when={itrKeys().length == 0}>
@@ -397,6 +414,13 @@ const YamlString: Component<YamlProps> = (props: YamlProps) => {
     )
 }
 
+// Who last set the field, e.g. "kubectl Update 2024-02-01T00:00:00Z"
+const ownersTitle = (owners: YamlProps['owners'], path: string): string | undefined => {
+    const fos = owners && owners[path]
+    if (!fos || fos.length == 0) return
+    return fos.map(o => [o.manager, o.operation, o.subresource, o.time].filter(v => v).join(' ')).join('\n')
+}
+
 const referenceMap = (refs: Array<KubeReference>): Map<string, KubeReference> => {
     const m = new Map<string, KubeReference>()
     refs.filter(r => r.Property.length > 0).forEach(r => { m.set(r.Property, r) })
@@ -2,90 +2,104 @@ package desktop
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"os"
+	"sync"
+	"time"
 
 	wailsruntime "github.com/wailsapp/wails/v2/pkg/runtime"
 
 	"bosun/pkg/desktop/store"
 	"bosun/pkg/desktop/tabs"
 	"bosun/pkg/kube"
+	"bosun/pkg/kube/relations"
 	"bosun/pkg/local"
+	"bosun/pkg/util"
 )
 
+// Tab changes arrive in bursts, e.g. navigating updates the path then the title.
+const TABS_WRITE_DELAY = 250 * time.Millisecond
+
+// Results for the jump to recent palette
+const RECENT_LIMIT = 50
+
 type FrontendApi struct {
-	ctx   context.Context
-	tabs  *tabs.Tabs
-	kubes *kube.Kubes
-	store *store.FileStore
+	ctx context.Context
+	// Wails calls methods concurrently. Guards tabs, which is replaced by SwitchWorkspace.
+	tabsLock   sync.Mutex
+	tabs       *tabs.Tabs
+	tabsWriter *util.Debouncer
+	kubes      *kube.Kubes
+	store      *store.FileStore
+	// The last query recorded in history, so refetches for sorting or filtering don't count again
+	queryLock sync.Mutex
+	lastQuery string
+	// Problems loading state, for the frontend to show
+	startupErrors []string
 }
 
 func MakeFrontendApi() *FrontendApi {
+	startupErrors := make([]string, 0)
+
 	fs, err := store.MakeFileStore()
 	if err != nil {
-		panic(err) // don't actually die
+		log.Error("MakeFileStore, falling back to state in memory", "error", err)
+		startupErrors = append(startupErrors, fmt.Sprintf("Unable to open saved state, changes won't be kept: %s", err))
+		fs = store.MakeMemoryFileStore()
 	}
 
 	t, found, err := fs.ReadTabs()
 	if err != nil {
 		// most likely file not found
 		log.Info("ReadTabs", "error", err)
+		if !errors.Is(err, os.ErrNotExist) {
+			startupErrors = append(startupErrors, fmt.Sprintf("Problem reading tabs: %s", err))
+		}
 	}
 	if !found {
 		t = tabs.MakeInitialTabs()
 	}
 
-	return &FrontendApi{
-		tabs:  t,
-		kubes: kube.MakeKube(),
-		store: fs,
+	rules, err := fs.ReadRelationRules()
+	if err != nil {
+		log.Error("ReadRelationRules", "error", err)
+		startupErrors = append(startupErrors, fmt.Sprintf("Problem reading relation rules: %s", err))
 	}
-}
-
-func (fa *FrontendApi) SetCtx(ctx context.Context) {
-	fa.ctx = ctx
-}
 
-// Greet returns a greeting for the given name
-func (fa *FrontendApi) Greet(name string) string {
-	return fmt.Sprintf("Hello %s, It's show time!", name)
-}
-
-func (fa *FrontendApi) Tabs() *tabs.Tabs {
-	return fa.tabs
-}
-
-func (fa *FrontendApi) SelectTab(id string) *tabs.Tabs {
-	fa.tabs.Current = id
-	return fa.tabs
-}
+	columnSets, err := fs.ReadColumnSets()
+	if err != nil {
+		log.Error("ReadColumnSets", "error", err)
+		startupErrors = append(startupErrors, fmt.Sprintf("Problem reading columns: %s", err))
+	}
 
-func (fa *FrontendApi) CloseTab(id string) *tabs.Tabs {
-	fa.tabs.CloseTab(id)
-	return fa.tabs
+	fa := &FrontendApi{
+		tabs:          t,
+		kubes:         kube.MakeKube(rules, columnSets),
+		store:         fs,
+		startupErrors: startupErrors,
+	}
+	fa.tabsWriter = util.NewDebouncer(TABS_WRITE_DELAY, fa.persistTabs)
+	return fa
 }
 
-func (fa *FrontendApi) PrevTab() *tabs.Tabs {
-	fa.tabs.PrevTab()
-	return fa.tabs
+// shutdown writes any pending changes
+func (fa *FrontendApi) shutdown() {
+	fa.tabsWriter.Flush()
 }
 
-func (fa *FrontendApi) NextTab() *tabs.Tabs {
-	fa.tabs.NextTab()
-	return fa.tabs
+func (fa *FrontendApi) SetCtx(ctx context.Context) {
+	fa.ctx = ctx
 }
 
-func (fa *FrontendApi) UpdateTab(id string, k8sCtx string, k8sNs string, path string, title string) *tabs.Tabs {
-	fa.tabs.Update(id, k8sCtx, k8sNs, path, title)
-	err := fa.store.WriteTabs(fa.tabs)
-	if err != nil {
-		log.Error("unable to WriteTabs", "error", err)
-	}
-	return fa.tabs
+// StartupErrors describes problems loading saved state, e.g. bosun is running with temporary state.
+func (fa *FrontendApi) StartupErrors() []string {
+	return fa.startupErrors
 }
 
-func (fa *FrontendApi) NewTab() *tabs.Tabs {
-	fa.tabs.NewTab()
-	return fa.tabs
+// Greet returns a greeting for the given name
+func (fa *FrontendApi) Greet(name string) string {
+	return fmt.Sprintf("Hello %s, It's show time!", name)
 }
 
 func (fa *FrontendApi) KubeContexts() []local.KubeContext {
@@ -109,21 +123,46 @@ func (fa *FrontendApi) KubeNamespaces(k8sCtx string) []string {
 	return ns
 }
 
-func (fa *FrontendApi) KubeResourceList(k8sCtx string, k8sNs string, query string) []kube.ResourceTable {
+// KubeResourceList runs query. opts can filter rows, sort every table by a column and add the wide
+// columns.
+func (fa *FrontendApi) KubeResourceList(k8sCtx string, k8sNs string, query string, opts kube.QueryOptions) []kube.ResourceTable {
 	kubeCluster, err := fa.kubes.GetOrMakeKubeCluster(k8sCtx)
 	if err != nil {
 		wailsruntime.LogErrorf(fa.ctx, "error getting cluster for name %s: %s", k8sCtx, err.Error())
 		return []kube.ResourceTable{}
 	}
 
-	resourceTables, err := kubeCluster.Query(fa.ctx, k8sNs, query)
+	resourceTables, err := kubeCluster.QueryWithOptions(fa.ctx, k8sNs, query, opts)
 	if err != nil {
 		wailsruntime.LogErrorf(fa.ctx, "error during query for %s %s %s: %s", k8sCtx, k8sNs, query, err.Error())
 		// return the tables. Maybe the error is for only one of them.
 	}
+	if resourceTables == nil {
+		resourceTables = []kube.ResourceTable{}
+	}
+
+	if err == nil {
+		fa.recordQuery(k8sCtx, k8sNs, query)
+	}
 	return resourceTables
 }
 
+// recordQuery adds a successful query to the history unless it's the same as the last one.
+func (fa *FrontendApi) recordQuery(k8sCtx string, k8sNs string, query string) {
+	key := fmt.Sprintf("%s/%s/%s", k8sCtx, k8sNs, query)
+	fa.queryLock.Lock()
+	defer fa.queryLock.Unlock()
+	if key == fa.lastQuery {
+		return
+	}
+
+	if err := fa.store.RecordQuery(k8sCtx, k8sNs, query); err != nil {
+		log.Error("unable to RecordQuery", "error", err)
+		return
+	}
+	fa.lastQuery = key
+}
+
 func (fa *FrontendApi) KubeResource(k8sCtx string, k8sNs string, group string, kind string, name string) *kube.Resource {
 	kubeCluster, err := fa.kubes.GetOrMakeKubeCluster(k8sCtx)
 	if err != nil {
@@ -136,5 +175,75 @@ func (fa *FrontendApi) KubeResource(k8sCtx string, k8sNs string, group string, k
 		wailsruntime.LogErrorf(fa.ctx, "error getting resource %s %s %s %s: %s", k8sCtx, k8sNs, kind, name, err.Error())
 		return &kube.Resource{}
 	}
+
+	if err := fa.store.RecordRecent(k8sCtx, k8sNs, group, kind, name); err != nil {
+		log.Error("unable to RecordRecent", "error", err)
+	}
 	return r
 }
+
+// RenderResource renders the object from KubeResource as full, clean or spec only YAML, or JSON.
+func (fa *FrontendApi) RenderResource(object map[string]interface{}, mode string) string {
+	text, err := kube.RenderObject(object, mode)
+	if err != nil {
+		log.Error("RenderResource", "mode", mode, "error", err)
+		return fmt.Sprintf("# unable to render: %s\n", err)
+	}
+	return text
+}
+
+// Explain documents a field of a kind from the cluster's OpenAPI schema, e.g. the path
+// .spec.template.spec.containers[].resources of apps Deployment.
+func (fa *FrontendApi) Explain(k8sCtx string, group string, kind string, path string) *kube.FieldDoc {
+	kubeCluster, err := fa.kubes.GetOrMakeKubeCluster(k8sCtx)
+	if err != nil {
+		wailsruntime.LogErrorf(fa.ctx, "error getting cluster for name %s: %s", k8sCtx, err.Error())
+		return &kube.FieldDoc{}
+	}
+
+	doc, err := kubeCluster.Explain(group, kind, path)
+	if err != nil {
+		log.Error("Explain", "group", group, "kind", kind, "path", path, "error", err)
+		return &kube.FieldDoc{Group: group, Kind: kind, Path: path}
+	}
+	return doc
+}
+
+// ValidateFilter returns the problem with a row filter expression, or an empty string.
+func (fa *FrontendApi) ValidateFilter(expr string) string {
+	if _, err := kube.ParseFilter(expr); err != nil {
+		return err.Error()
+	}
+	return ""
+}
+
+// RecentResources searches the recently viewed resources in k8sCtx, or all contexts if it's empty.
+func (fa *FrontendApi) RecentResources(k8sCtx string, query string) []*store.RecentResource {
+	recent, err := fa.store.SearchRecent(k8sCtx, query, RECENT_LIMIT)
+	if err != nil {
+		log.Error("unable to SearchRecent", "error", err)
+		return []*store.RecentResource{}
+	}
+	return recent
+}
+
+// RelationGraph of references out to depth hops. With an empty kind it starts from the whole namespace.
+func (fa *FrontendApi) RelationGraph(k8sCtx string, k8sNs string, group string, kind string, name string, depth int) *relations.Graph {
+	kubeCluster, err := fa.kubes.GetOrMakeKubeCluster(k8sCtx)
+	if err != nil {
+		wailsruntime.LogErrorf(fa.ctx, "error getting cluster for name %s: %s", k8sCtx, err.Error())
+		return relations.MakeGraph()
+	}
+
+	var g *relations.Graph
+	if kind == "" {
+		g, err = kubeCluster.NamespaceRelationGraph(fa.ctx, k8sNs, depth)
+	} else {
+		g, err = kubeCluster.RelationGraph(fa.ctx, k8sNs, group, kind, name, depth)
+	}
+	if err != nil {
+		wailsruntime.LogErrorf(fa.ctx, "error building relation graph %s %s %s %s: %s", k8sCtx, k8sNs, kind, name, err.Error())
+		return relations.MakeGraph()
+	}
+	return g
+}
@@ -0,0 +1,53 @@
+package desktop
+
+import (
+	wailsruntime "github.com/wailsapp/wails/v2/pkg/runtime"
+
+	"bosun/pkg/kube"
+)
+
+// ChooseDirectory opens a dialog to pick a backup directory. It's empty when cancelled.
+func (fa *FrontendApi) ChooseDirectory(title string) string {
+	dir, err := wailsruntime.OpenDirectoryDialog(fa.ctx, wailsruntime.OpenDialogOptions{
+		Title:                title,
+		CanCreateDirectories: true,
+	})
+	if err != nil {
+		log.Error("OpenDirectoryDialog", "error", err)
+		return ""
+	}
+	return dir
+}
+
+// BackupNamespace writes the namespace's objects, or only those of kinds, to dir as clean manifests.
+func (fa *FrontendApi) BackupNamespace(k8sCtx string, k8sNs string, kinds []string, dir string) *kube.BackupResult {
+	kubeCluster, err := fa.kubes.GetOrMakeKubeCluster(k8sCtx)
+	if err != nil {
+		wailsruntime.LogErrorf(fa.ctx, "error getting cluster for name %s: %s", k8sCtx, err.Error())
+		return &kube.BackupResult{Dir: dir, Skipped: []string{}, Errors: []string{err.Error()}}
+	}
+
+	result, err := kubeCluster.BackupNamespace(fa.ctx, k8sNs, kinds, dir)
+	if err != nil {
+		log.Error("BackupNamespace", "error", err)
+		return &kube.BackupResult{Dir: dir, Skipped: []string{}, Errors: []string{err.Error()}}
+	}
+	return result
+}
+
+// ApplyManifests applies the manifests in dir to the namespace once they all pass a dry run. With
+// dryRun it only reports what the server would reject.
+func (fa *FrontendApi) ApplyManifests(k8sCtx string, k8sNs string, dir string, dryRun bool) []kube.ApplyResult {
+	kubeCluster, err := fa.kubes.GetOrMakeKubeCluster(k8sCtx)
+	if err != nil {
+		wailsruntime.LogErrorf(fa.ctx, "error getting cluster for name %s: %s", k8sCtx, err.Error())
+		return []kube.ApplyResult{{File: dir, Namespace: k8sNs, DryRun: dryRun, Error: err.Error()}}
+	}
+
+	results, err := kubeCluster.ApplyManifests(fa.ctx, dir, k8sNs, dryRun)
+	if err != nil {
+		log.Error("ApplyManifests", "error", err)
+		return []kube.ApplyResult{{File: dir, Namespace: k8sNs, DryRun: dryRun, Error: err.Error()}}
+	}
+	return results
+}
@@ -0,0 +1,106 @@
+package desktop
+
+import (
+	"bosun/pkg/desktop/store"
+	"bosun/pkg/desktop/tabs"
+)
+
+func (fa *FrontendApi) Bookmarks() []*store.Bookmark {
+	bms, err := fa.store.ReadBookmarks()
+	if err != nil {
+		log.Error("unable to ReadBookmarks", "error", err)
+		return []*store.Bookmark{}
+	}
+	return bms
+}
+
+func (fa *FrontendApi) BookmarkFolders() []string {
+	return store.BookmarkFolders(fa.Bookmarks())
+}
+
+func (fa *FrontendApi) AddResourceBookmark(k8sCtx string, k8sNs string, group string, kind string, name string, title string, folder string) []*store.Bookmark {
+	return fa.addBookmark(store.Bookmark{
+		Title:        title,
+		Folder:       folder,
+		K8sContext:   k8sCtx,
+		K8sNamespace: k8sNs,
+		Group:        group,
+		Kind:         kind,
+		Name:         name,
+	})
+}
+
+func (fa *FrontendApi) AddQueryBookmark(k8sCtx string, k8sNs string, query string, title string, folder string) []*store.Bookmark {
+	return fa.addBookmark(store.Bookmark{
+		Title:        title,
+		Folder:       folder,
+		K8sContext:   k8sCtx,
+		K8sNamespace: k8sNs,
+		Query:        query,
+	})
+}
+
+func (fa *FrontendApi) addBookmark(b store.Bookmark) []*store.Bookmark {
+	if _, err := fa.store.AddBookmark(b); err != nil {
+		log.Error("unable to AddBookmark", "bookmark", b, "error", err)
+	}
+	return fa.Bookmarks()
+}
+
+// UpdateBookmark renames a bookmark or moves it to another folder.
+func (fa *FrontendApi) UpdateBookmark(id string, title string, folder string) []*store.Bookmark {
+	bms, err := fa.store.UpdateBookmark(id, title, folder)
+	if err != nil {
+		log.Error("unable to UpdateBookmark", "id", id, "error", err)
+		return fa.Bookmarks()
+	}
+	return bms
+}
+
+func (fa *FrontendApi) DeleteBookmark(id string) []*store.Bookmark {
+	bms, err := fa.store.DeleteBookmark(id)
+	if err != nil {
+		log.Error("unable to DeleteBookmark", "id", id, "error", err)
+		return fa.Bookmarks()
+	}
+	return bms
+}
+
+// OpenBookmark opens the bookmark in a new tab.
+func (fa *FrontendApi) OpenBookmark(id string) *tabs.Tabs {
+	var found *store.Bookmark
+	for _, b := range fa.Bookmarks() {
+		if b.Id == id {
+			found = b
+		}
+	}
+
+	return fa.mutateTabs(func(t *tabs.Tabs) bool {
+		if found == nil {
+			log.Error("OpenBookmark not found", "id", id)
+			return false
+		}
+		t.OpenTab(found.K8sContext, found.K8sNamespace, found.Path(), found.Title)
+		return true
+	})
+}
+
+// ExportBookmarks returns YAML of the bookmarks in folder, or all of them when folder is empty.
+func (fa *FrontendApi) ExportBookmarks(folder string) string {
+	data, err := fa.store.ExportBookmarks(folder)
+	if err != nil {
+		log.Error("unable to ExportBookmarks", "folder", folder, "error", err)
+		return ""
+	}
+	return data
+}
+
+// ImportBookmarks adds bookmarks from YAML produced by ExportBookmarks, skipping duplicates.
+func (fa *FrontendApi) ImportBookmarks(data string) []*store.Bookmark {
+	added, err := fa.store.ImportBookmarks(data)
+	if err != nil {
+		log.Error("unable to ImportBookmarks", "error", err)
+	}
+	log.Info("ImportBookmarks", "added", added)
+	return fa.Bookmarks()
+}
@@ -0,0 +1,78 @@
+package desktop
+
+import (
+	"strings"
+
+	"bosun/pkg/kube"
+	"bosun/pkg/local"
+)
+
+// Completions returned to the command bar
+const COMPLETION_LIMIT = 20
+
+// Words the command bar understands before a query. See evalCommand in the frontend.
+var commands = []string{"ctx", "ns"}
+
+// Complete returns ranked completions for the command bar's partial input:
+//
+//	"ct"        -> commands and kinds
+//	"deploy,sv" -> kinds after the last comma
+//	"ctx ki"    -> context names
+//	"ns def"    -> namespaces in k8sCtx
+//	"deploy/we" -> names of deployments in k8sNs
+//	"po,svc/we" -> names of services, after the last comma
+func (fa *FrontendApi) Complete(k8sCtx string, k8sNs string, partial string) []kube.Completion {
+	cs := make([]kube.Completion, 0)
+
+	first, rest, hasSpace := strings.Cut(strings.TrimLeft(partial, " "), " ")
+	switch {
+	case hasSpace && first == "ctx":
+		for _, kctx := range local.KubeContexts() {
+			if score, ok := kube.ScoreCompletion(kctx.Name, rest); ok {
+				cs = append(cs, kube.Completion{Text: "ctx " + kctx.Name, Type: kube.CompletionContext, Detail: kctx.Cluster, Score: score})
+			}
+		}
+
+	case hasSpace && first == "ns":
+		if kubeCluster := fa.completionCluster(k8sCtx); kubeCluster != nil {
+			nss, err := kubeCluster.CompleteNamespaces(fa.ctx, rest)
+			if err != nil {
+				log.Error("CompleteNamespaces", "error", err)
+			}
+			cs = append(cs, nss...)
+		}
+
+	case hasSpace:
+		// Queries are a single word
+
+	default:
+		if !strings.ContainsAny(first, ",/") {
+			for _, cmd := range commands {
+				if score, ok := kube.ScoreCompletion(cmd, first); ok {
+					cs = append(cs, kube.Completion{Text: cmd + " ", Type: kube.CompletionCommand, Score: score})
+				}
+			}
+		}
+		if kubeCluster := fa.completionCluster(k8sCtx); kubeCluster != nil {
+			qs, err := kubeCluster.CompleteQuery(fa.ctx, k8sNs, first)
+			if err != nil {
+				log.Error("CompleteQuery", "error", err)
+			}
+			cs = append(cs, qs...)
+		}
+	}
+
+	return kube.SortCompletions(cs, COMPLETION_LIMIT)
+}
+
+func (fa *FrontendApi) completionCluster(k8sCtx string) *kube.KubeCluster {
+	if k8sCtx == "" {
+		return nil
+	}
+	kubeCluster, err := fa.kubes.GetOrMakeKubeCluster(k8sCtx)
+	if err != nil {
+		log.Error("completionCluster", "k8sCtx", k8sCtx, "error", err)
+		return nil
+	}
+	return kubeCluster
+}
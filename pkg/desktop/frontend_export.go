@@ -0,0 +1,75 @@
+package desktop
+
+import (
+	"fmt"
+	"os"
+
+	wailsruntime "github.com/wailsapp/wails/v2/pkg/runtime"
+
+	"bosun/pkg/kube"
+)
+
+// Where exports are written
+const (
+	EXPORT_CLIPBOARD = "clipboard"
+	EXPORT_FILE      = "file"
+)
+
+// ExportTable writes a table from KubeResourceList, as displayed, to the clipboard or a file chosen
+// in a save dialog. It returns the problem, or an empty string.
+func (fa *FrontendApi) ExportTable(table kube.ResourceTable, format string, destination string) string {
+	content, err := kube.ExportTable(table, format)
+	if err != nil {
+		log.Error("ExportTable", "error", err)
+		return err.Error()
+	}
+	return fa.export(content, table.APIResource.Name+kube.FormatExtension(format), destination)
+}
+
+// ExportResources writes the refs as multi document YAML or a v1 List to the clipboard or a file.
+// It returns the problem, or an empty string.
+func (fa *FrontendApi) ExportResources(k8sCtx string, refs []kube.ResourceRef, format string, destination string) string {
+	kubeCluster, err := fa.kubes.GetOrMakeKubeCluster(k8sCtx)
+	if err != nil {
+		wailsruntime.LogErrorf(fa.ctx, "error getting cluster for name %s: %s", k8sCtx, err.Error())
+		return err.Error()
+	}
+
+	content, err := kubeCluster.ExportResources(fa.ctx, refs, format)
+	if err != nil {
+		log.Error("ExportResources", "error", err)
+		return err.Error()
+	}
+	return fa.export(content, "resources"+kube.FormatExtension(format), destination)
+}
+
+func (fa *FrontendApi) export(content string, filename string, destination string) string {
+	switch destination {
+	case EXPORT_CLIPBOARD:
+		if err := wailsruntime.ClipboardSetText(fa.ctx, content); err != nil {
+			log.Error("unable to set clipboard", "error", err)
+			return fmt.Sprintf("Unable to copy to the clipboard: %s", err)
+		}
+		return ""
+
+	case EXPORT_FILE:
+		path, err := wailsruntime.SaveFileDialog(fa.ctx, wailsruntime.SaveDialogOptions{
+			DefaultFilename:      filename,
+			CanCreateDirectories: true,
+		})
+		if err != nil {
+			log.Error("SaveFileDialog", "error", err)
+			return fmt.Sprintf("Unable to choose a file: %s", err)
+		}
+		if path == "" {
+			// Cancelled
+			return ""
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			log.Error("unable to write export", "path", path, "error", err)
+			return fmt.Sprintf("Unable to write %s: %s", path, err)
+		}
+		return ""
+	}
+	return fmt.Sprintf("Unknown export destination %s", destination)
+}
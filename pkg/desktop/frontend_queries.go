@@ -0,0 +1,92 @@
+package desktop
+
+import (
+	wailsruntime "github.com/wailsapp/wails/v2/pkg/runtime"
+
+	"bosun/pkg/desktop/store"
+	"bosun/pkg/kube"
+)
+
+// Queries returned by QueryHistory
+const QUERY_HISTORY_LIMIT = 25
+
+// QueryHistory returns the queries run in the context and namespace, most frequent first.
+func (fa *FrontendApi) QueryHistory(k8sCtx string, k8sNs string) []*store.QueryHistoryEntry {
+	history, err := fa.store.QueryHistory(k8sCtx, k8sNs, QUERY_HISTORY_LIMIT)
+	if err != nil {
+		log.Error("unable to read QueryHistory", "error", err)
+		return []*store.QueryHistoryEntry{}
+	}
+	return history
+}
+
+func (fa *FrontendApi) SavedQueries() []*store.SavedQuery {
+	saved, err := fa.store.SavedQueries()
+	if err != nil {
+		log.Error("unable to read SavedQueries", "error", err)
+		return []*store.SavedQuery{}
+	}
+	return saved
+}
+
+// SaveQuery names a query. Saving an existing name in the context replaces it.
+func (fa *FrontendApi) SaveQuery(name string, k8sCtx string, k8sNs string, query string, labelSelector string, fieldSelector string) []*store.SavedQuery {
+	_, err := fa.store.SaveQuery(store.SavedQuery{
+		Name:          name,
+		K8sContext:    k8sCtx,
+		K8sNamespace:  k8sNs,
+		Query:         query,
+		LabelSelector: labelSelector,
+		FieldSelector: fieldSelector,
+	})
+	if err != nil {
+		log.Error("unable to SaveQuery", "name", name, "error", err)
+	}
+	return fa.SavedQueries()
+}
+
+func (fa *FrontendApi) DeleteSavedQuery(id string) []*store.SavedQuery {
+	saved, err := fa.store.DeleteSavedQuery(id)
+	if err != nil {
+		log.Error("unable to DeleteSavedQuery", "id", id, "error", err)
+		return fa.SavedQueries()
+	}
+	return saved
+}
+
+// RunSavedQuery lists the saved query's resources in its own context and namespace.
+func (fa *FrontendApi) RunSavedQuery(id string) []kube.ResourceTable {
+	var found *store.SavedQuery
+	for _, q := range fa.SavedQueries() {
+		if q.Id == id {
+			found = q
+		}
+	}
+	if found == nil {
+		log.Error("RunSavedQuery not found", "id", id)
+		return []kube.ResourceTable{}
+	}
+
+	kubeCluster, err := fa.kubes.GetOrMakeKubeCluster(found.K8sContext)
+	if err != nil {
+		wailsruntime.LogErrorf(fa.ctx, "error getting cluster for name %s: %s", found.K8sContext, err.Error())
+		return []kube.ResourceTable{}
+	}
+
+	opts := kube.QueryOptions{
+		Selectors: kube.Selectors{Label: found.LabelSelector, Field: found.FieldSelector},
+	}
+	resourceTables, err := kubeCluster.QueryWithOptions(fa.ctx, found.K8sNamespace, found.Query, opts)
+	if err != nil {
+		wailsruntime.LogErrorf(fa.ctx, "error during saved query %s: %s", found.Name, err.Error())
+		// return the tables. Maybe the error is for only one of them.
+	}
+	if resourceTables == nil {
+		resourceTables = []kube.ResourceTable{}
+	}
+
+	if err == nil {
+		fa.recordQuery(found.K8sContext, found.K8sNamespace, found.Query)
+	}
+	return resourceTables
+}
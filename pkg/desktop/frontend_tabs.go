@@ -0,0 +1,221 @@
+package desktop
+
+import (
+	"bosun/pkg/desktop/tabs"
+)
+
+// mutateTabs applies f under the tabs lock and schedules a write when f reports a change. The
+// frontend gets a copy since Wails serializes the result after the lock is released.
+func (fa *FrontendApi) mutateTabs(f func(t *tabs.Tabs) bool) *tabs.Tabs {
+	fa.tabsLock.Lock()
+	defer fa.tabsLock.Unlock()
+
+	if f(fa.tabs) {
+		fa.tabsWriter.Trigger()
+	}
+	return fa.tabs.Clone()
+}
+
+func (fa *FrontendApi) persistTabs() {
+	fa.tabsLock.Lock()
+	defer fa.tabsLock.Unlock()
+
+	err := fa.store.WriteTabs(fa.tabs)
+	if err != nil {
+		log.Error("unable to WriteTabs", "error", err)
+	}
+}
+
+func (fa *FrontendApi) Tabs() *tabs.Tabs {
+	return fa.mutateTabs(func(t *tabs.Tabs) bool {
+		return false
+	})
+}
+
+func (fa *FrontendApi) SelectTab(id string) *tabs.Tabs {
+	return fa.mutateTabs(func(t *tabs.Tabs) bool {
+		t.SelectTab(id)
+		return true
+	})
+}
+
+func (fa *FrontendApi) CloseTab(id string) *tabs.Tabs {
+	return fa.mutateTabs(func(t *tabs.Tabs) bool {
+		t.CloseTab(id)
+		return true
+	})
+}
+
+func (fa *FrontendApi) MoveTab(id string, idx int) *tabs.Tabs {
+	return fa.mutateTabs(func(t *tabs.Tabs) bool {
+		return t.MoveTab(id, idx)
+	})
+}
+
+func (fa *FrontendApi) DuplicateTab(id string) *tabs.Tabs {
+	return fa.mutateTabs(func(t *tabs.Tabs) bool {
+		return t.DuplicateTab(id)
+	})
+}
+
+func (fa *FrontendApi) PinTab(id string, pinned bool) *tabs.Tabs {
+	return fa.mutateTabs(func(t *tabs.Tabs) bool {
+		return t.PinTab(id, pinned)
+	})
+}
+
+func (fa *FrontendApi) ReopenClosedTab() *tabs.Tabs {
+	return fa.mutateTabs(func(t *tabs.Tabs) bool {
+		return t.ReopenClosedTab()
+	})
+}
+
+// ClosedTabs lists the tabs that can be restored, most recently closed first.
+func (fa *FrontendApi) ClosedTabs() []*tabs.Tab {
+	t := fa.Tabs()
+	closed := make([]*tabs.Tab, 0, len(t.Closed))
+	for i := len(t.Closed) - 1; i >= 0; i-- {
+		closed = append(closed, t.Closed[i].Tab)
+	}
+	return closed
+}
+
+func (fa *FrontendApi) RestoreClosedTab(id string) *tabs.Tabs {
+	return fa.mutateTabs(func(t *tabs.Tabs) bool {
+		return t.RestoreClosedTab(id)
+	})
+}
+
+func (fa *FrontendApi) PrevTab() *tabs.Tabs {
+	return fa.mutateTabs(func(t *tabs.Tabs) bool {
+		t.PrevTab()
+		return true
+	})
+}
+
+func (fa *FrontendApi) NextTab() *tabs.Tabs {
+	return fa.mutateTabs(func(t *tabs.Tabs) bool {
+		t.NextTab()
+		return true
+	})
+}
+
+func (fa *FrontendApi) UpdateTab(id string, k8sCtx string, k8sNs string, path string, title string) *tabs.Tabs {
+	return fa.mutateTabs(func(t *tabs.Tabs) bool {
+		t.Update(id, k8sCtx, k8sNs, path, title)
+		return true
+	})
+}
+
+func (fa *FrontendApi) Back(id string) *tabs.Tabs {
+	return fa.mutateTabs(func(t *tabs.Tabs) bool {
+		return t.GoBack(id)
+	})
+}
+
+func (fa *FrontendApi) Forward(id string) *tabs.Tabs {
+	return fa.mutateTabs(func(t *tabs.Tabs) bool {
+		return t.GoForward(id)
+	})
+}
+
+func (fa *FrontendApi) NewTab() *tabs.Tabs {
+	return fa.mutateTabs(func(t *tabs.Tabs) bool {
+		t.NewTab()
+		return true
+	})
+}
+
+func (fa *FrontendApi) Workspaces() []string {
+	names, err := fa.store.ListWorkspaces()
+	if err != nil {
+		log.Error("unable to ListWorkspaces", "error", err)
+		return []string{}
+	}
+	return names
+}
+
+// SaveWorkspace saves the current tabs under name and makes it the current workspace.
+func (fa *FrontendApi) SaveWorkspace(name string) []string {
+	fa.mutateTabs(func(t *tabs.Tabs) bool {
+		prev := t.Workspace
+		t.Workspace = name
+		if err := fa.store.WriteWorkspace(name, t); err != nil {
+			log.Error("unable to WriteWorkspace", "name", name, "error", err)
+			t.Workspace = prev
+			return false
+		}
+		return true
+	})
+	return fa.Workspaces()
+}
+
+// SwitchWorkspace saves the current tabs, as their workspace or as the unnamed workspace, before
+// replacing them with the named one. An empty name switches back to the unnamed workspace. The
+// tabs aren't replaced when they can't be saved.
+func (fa *FrontendApi) SwitchWorkspace(name string) *tabs.Tabs {
+	return fa.mutateTabs(func(t *tabs.Tabs) bool {
+		var next *tabs.Tabs
+		var found bool
+		var err error
+		if name == "" {
+			next, found, err = fa.store.ReadUnnamedWorkspace()
+			if err == nil && !found {
+				next, found = tabs.MakeInitialTabs(), true
+			}
+		} else {
+			next, found, err = fa.store.ReadWorkspace(name)
+		}
+		if err != nil || !found {
+			log.Error("unable to ReadWorkspace", "name", name, "found", found, "error", err)
+			return false
+		}
+
+		if t.Workspace == "" {
+			err = fa.store.WriteUnnamedWorkspace(t)
+		} else {
+			err = fa.store.WriteWorkspace(t.Workspace, t)
+		}
+		if err != nil {
+			log.Error("unable to save workspace before switching", "name", t.Workspace, "error", err)
+			return false
+		}
+
+		if len(next.All) == 0 {
+			next.NewTab()
+		}
+		next.Workspace = name
+		fa.tabs = next
+		return true
+	})
+}
+
+func (fa *FrontendApi) RenameWorkspace(oldName string, newName string) []string {
+	fa.mutateTabs(func(t *tabs.Tabs) bool {
+		if err := fa.store.RenameWorkspace(oldName, newName); err != nil {
+			log.Error("unable to RenameWorkspace", "oldName", oldName, "newName", newName, "error", err)
+			return false
+		}
+		if t.Workspace != oldName {
+			return false
+		}
+		t.Workspace = newName
+		return true
+	})
+	return fa.Workspaces()
+}
+
+func (fa *FrontendApi) DeleteWorkspace(name string) []string {
+	fa.mutateTabs(func(t *tabs.Tabs) bool {
+		if err := fa.store.DeleteWorkspace(name); err != nil {
+			log.Error("unable to DeleteWorkspace", "name", name, "error", err)
+			return false
+		}
+		if t.Workspace != name {
+			return false
+		}
+		t.Workspace = ""
+		return true
+	})
+	return fa.Workspaces()
+}
@@ -0,0 +1,45 @@
+package desktop
+
+import (
+	"testing"
+
+	"bosun/pkg/desktop/store"
+	"bosun/pkg/desktop/tabs"
+	"bosun/pkg/util"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func mockFrontendApi() *FrontendApi {
+	fa := &FrontendApi{
+		tabs:  tabs.MakeInitialTabs(),
+		store: store.MakeMemoryFileStore(),
+	}
+	fa.tabsWriter = util.NewDebouncer(TABS_WRITE_DELAY, fa.persistTabs)
+	return fa
+}
+
+func TestSwitchWorkspace(t *testing.T) {
+	fa := mockFrontendApi()
+	unnamed := fa.UpdateTab(fa.Tabs().Current, "kind", "default", "/kind/default", "default")
+	fa.NewTab()
+
+	ops := tabs.MakeInitialTabs()
+	ops.Update(ops.Current, "prod", "ops", "/prod/ops", "ops")
+	assert.NoError(t, fa.store.WriteWorkspace("ops", ops))
+
+	switched := fa.SwitchWorkspace("ops")
+	assert.Equal(t, "ops", switched.Workspace)
+	assert.Len(t, switched.All, 1)
+	assert.Equal(t, "/prod/ops", switched.All[0].Path)
+	assert.Equal(t, []string{"ops"}, fa.Workspaces())
+
+	// Back to the unnamed tabs
+	back := fa.SwitchWorkspace("")
+	assert.Equal(t, "", back.Workspace)
+	assert.Len(t, back.All, 2)
+	assert.Equal(t, unnamed.All[0].Path, back.All[0].Path)
+
+	// Nothing to switch to
+	assert.Len(t, fa.SwitchWorkspace("missing").All, 2)
+}
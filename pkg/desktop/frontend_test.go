@@ -0,0 +1,24 @@
+package desktop
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecordQuery(t *testing.T) {
+	fa := mockFrontendApi()
+
+	// Refetches of the same query, e.g. after sorting, count once
+	fa.recordQuery("kind", "default", "pods")
+	fa.recordQuery("kind", "default", "pods")
+	fa.recordQuery("kind", "default", "deploy")
+	fa.recordQuery("kind", "default", "pods")
+
+	history, err := fa.store.QueryHistory("kind", "default", 10)
+	assert.NoError(t, err)
+	assert.Len(t, history, 2)
+	assert.Equal(t, "pods", history[0].Query)
+	assert.Equal(t, 2, history[0].Count)
+	assert.Equal(t, 1, history[1].Count)
+}
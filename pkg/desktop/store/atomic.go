@@ -0,0 +1,122 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"bosun/pkg/desktop/tabs"
+	"bosun/pkg/logging"
+
+	"github.com/goccy/go-yaml"
+)
+
+var log = logging.Default()
+
+const (
+	BACKUP_EXT  = ".bak"
+	CORRUPT_EXT = ".corrupt"
+	NEWER_EXT   = ".newer"
+)
+
+var errCorrupt = errors.New("corrupt file")
+
+func backupFile(file string) string {
+	return file + BACKUP_EXT
+}
+
+func readTabsFile(files files, file string) (*tabs.Tabs, bool, error) {
+	doc := &tabsDoc{}
+	found, err := readVersioned(files, file, tabsMigrations, doc)
+	if err != nil || !found {
+		return nil, found, err
+	}
+	return &doc.Tabs, true, nil
+}
+
+func writeTabsFile(files files, file string, t *tabs.Tabs) error {
+	return writeVersioned(files, file, &tabsDoc{Version: TABS_SCHEMA_VERSION, Tabs: *t})
+}
+
+// readVersioned migrates file's content to the latest version before unmarshalling it into doc.
+// An empty file is not found.
+func readVersioned(files files, file string, migrations []migration, doc interface{}) (bool, error) {
+	data, err := files.ReadFile(file)
+	if err != nil {
+		return false, fmt.Errorf("unable to read %s: %w", file, err)
+	}
+
+	if len(data) == 0 {
+		return false, nil
+	}
+
+	// Normally MakeFileStore has already migrated the file. Backups and imports may be older.
+	data, _, err = migrate(data, migrations)
+	if err != nil {
+		return false, fmt.Errorf("unable to read %s: %w", file, err)
+	}
+
+	if err := yaml.Unmarshal(data, doc); err != nil {
+		return false, fmt.Errorf("unable to unmarshal %s: %w: %w", file, errCorrupt, err)
+	}
+
+	return true, nil
+}
+
+// writeVersioned replaces file atomically after copying the current version, if it's readable, to
+// the backup file.
+func writeVersioned(files files, file string, doc interface{}) error {
+	bs, err := yaml.Marshal(doc)
+	if err != nil {
+		return fmt.Errorf("unable to marshal %s: %w", filepath.Base(file), err)
+	}
+
+	// Don't replace a good backup with a corrupt file
+	if current, err := files.ReadFile(file); err == nil && isYaml(current) {
+		if err := files.WriteFile(backupFile(file), current); err != nil {
+			log.Error("unable to back up", "file", file, "error", err)
+		}
+	}
+
+	if err := files.WriteFile(file, bs); err != nil {
+		return fmt.Errorf("unable to write %s: %w", filepath.Base(file), err)
+	}
+
+	return nil
+}
+
+func isYaml(data []byte) bool {
+	doc := map[string]interface{}{}
+	return len(data) > 0 && yaml.Unmarshal(data, &doc) == nil
+}
+
+// writeFileAtomic writes to a temp file in the same directory and renames it over file so readers
+// see either the old or the new content, never a partial write.
+func writeFileAtomic(file string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(file), filepath.Base(file)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("unable to create temp file for %s: %w", file, err)
+	}
+	defer os.Remove(tmp.Name()) // no-op after a successful rename
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("unable to write %s: %w", tmp.Name(), err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("unable to sync %s: %w", tmp.Name(), err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("unable to close %s: %w", tmp.Name(), err)
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return fmt.Errorf("unable to chmod %s: %w", tmp.Name(), err)
+	}
+
+	if err := os.Rename(tmp.Name(), file); err != nil {
+		return fmt.Errorf("unable to rename %s to %s: %w", tmp.Name(), file, err)
+	}
+	return nil
+}
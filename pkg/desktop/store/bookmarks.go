@@ -0,0 +1,218 @@
+package store
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/dchest/uniuri"
+	"github.com/goccy/go-yaml"
+)
+
+// Bookmark is either a single resource or a query, in a context and namespace.
+type Bookmark struct {
+	Id           string    `yaml:"id,omitempty"`
+	Title        string    `yaml:"title"`
+	Folder       string    `yaml:"folder,omitempty"`
+	K8sContext   string    `yaml:"k8sContext"`
+	K8sNamespace string    `yaml:"k8sNamespace,omitempty"`
+	Group        string    `yaml:"group,omitempty"`
+	Kind         string    `yaml:"kind,omitempty"`
+	Name         string    `yaml:"name,omitempty"`
+	Query        string    `yaml:"query,omitempty"`
+	CreatedAt    time.Time `yaml:"createdAt,omitempty"`
+}
+
+type bookmarksDoc struct {
+	Versioned `yaml:",inline"`
+	Bookmarks []*Bookmark `yaml:"bookmarks"`
+}
+
+var bookmarksDocs = docFile[*bookmarksDoc]{
+	path:       func(fs *FileStore) string { return fs.bookmarksFile },
+	migrations: []migration{noMigration},
+	empty:      func() *bookmarksDoc { return &bookmarksDoc{Bookmarks: []*Bookmark{}} },
+}
+
+func (b *Bookmark) IsResource() bool {
+	return b.Kind != "" && b.Name != ""
+}
+
+// Path is the frontend route that shows the bookmark.
+func (b *Bookmark) Path() string {
+	if b.IsResource() {
+		return resourcePath(b.K8sContext, b.K8sNamespace, b.Group, b.Kind, b.Name)
+	}
+	params := url.Values{}
+	params.Set("k8sCtx", b.K8sContext)
+	params.Set("k8sNs", b.K8sNamespace)
+	params.Set("query", b.Query)
+	return "/resources?" + params.Encode()
+}
+
+// resourcePath matches the frontend's pathResource
+func resourcePath(k8sCtx string, k8sNs string, group string, kind string, name string) string {
+	params := url.Values{}
+	params.Set("k8sCtx", k8sCtx)
+	params.Set("k8sNs", k8sNs)
+	params.Set("group", group)
+	params.Set("kind", kind)
+	params.Set("name", name)
+	return "/resource?" + params.Encode()
+}
+
+func (b *Bookmark) validate() error {
+	if b.K8sContext == "" {
+		return fmt.Errorf("bookmark %s has no context", b.Title)
+	}
+	if !b.IsResource() && b.Query == "" {
+		return fmt.Errorf("bookmark %s needs a kind and name or a query", b.Title)
+	}
+	return nil
+}
+
+// sameTarget ignores the Id, Title and CreatedAt so imports don't duplicate bookmarks.
+func (b *Bookmark) sameTarget(o *Bookmark) bool {
+	return b.Folder == o.Folder &&
+		b.K8sContext == o.K8sContext &&
+		b.K8sNamespace == o.K8sNamespace &&
+		b.Group == o.Group &&
+		b.Kind == o.Kind &&
+		b.Name == o.Name &&
+		b.Query == o.Query
+}
+
+func (fs *FileStore) ReadBookmarks() ([]*Bookmark, error) {
+	fs.lock.Lock()
+	defer fs.lock.Unlock()
+	doc, err := bookmarksDocs.read(fs)
+	if err != nil {
+		return nil, err
+	}
+	return doc.Bookmarks, nil
+}
+
+// modifyBookmarks is a read, modify, write of the bookmarks under the store's lock.
+func (fs *FileStore) modifyBookmarks(f func([]*Bookmark) ([]*Bookmark, error)) ([]*Bookmark, error) {
+	doc, err := bookmarksDocs.modify(fs, func(doc *bookmarksDoc) error {
+		bms, err := f(doc.Bookmarks)
+		doc.Bookmarks = bms
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return doc.Bookmarks, nil
+}
+
+func (fs *FileStore) AddBookmark(b Bookmark) (*Bookmark, error) {
+	if err := b.validate(); err != nil {
+		return nil, err
+	}
+	b.Id = uniuri.New()
+	b.CreatedAt = time.Now()
+
+	_, err := fs.modifyBookmarks(func(bms []*Bookmark) ([]*Bookmark, error) {
+		return append(bms, &b), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &b, nil
+}
+
+// UpdateBookmark changes the title and folder of a bookmark.
+func (fs *FileStore) UpdateBookmark(id string, title string, folder string) ([]*Bookmark, error) {
+	return fs.modifyBookmarks(func(bms []*Bookmark) ([]*Bookmark, error) {
+		idx := slices.IndexFunc(bms, func(b *Bookmark) bool { return b.Id == id })
+		if idx == -1 {
+			return nil, fmt.Errorf("no bookmark with id %s", id)
+		}
+		bms[idx].Title = title
+		bms[idx].Folder = folder
+		return bms, nil
+	})
+}
+
+func (fs *FileStore) DeleteBookmark(id string) ([]*Bookmark, error) {
+	return fs.modifyBookmarks(func(bms []*Bookmark) ([]*Bookmark, error) {
+		return slices.DeleteFunc(bms, func(b *Bookmark) bool { return b.Id == id }), nil
+	})
+}
+
+// BookmarkFolders returns the distinct folders, sorted. Folders exist as long as a bookmark is in them.
+func BookmarkFolders(bms []*Bookmark) []string {
+	folders := make([]string, 0)
+	for _, b := range bms {
+		if b.Folder != "" && !slices.Contains(folders, b.Folder) {
+			folders = append(folders, b.Folder)
+		}
+	}
+	sort.Strings(folders)
+	return folders
+}
+
+// ExportBookmarks serializes the bookmarks in folder, or all of them, for sharing.
+func (fs *FileStore) ExportBookmarks(folder string) (string, error) {
+	bms, err := fs.ReadBookmarks()
+	if err != nil {
+		return "", err
+	}
+
+	exported := make([]*Bookmark, 0, len(bms))
+	for _, b := range bms {
+		if folder == "" || b.Folder == folder {
+			// Ids are local to this store
+			c := *b
+			c.Id = ""
+			exported = append(exported, &c)
+		}
+	}
+
+	doc := &bookmarksDoc{Bookmarks: exported}
+	doc.setVersion(bookmarksDocs.version())
+	bs, err := yaml.Marshal(doc)
+	if err != nil {
+		return "", fmt.Errorf("unable to marshal bookmarks: %w", err)
+	}
+	return string(bs), nil
+}
+
+// ImportBookmarks adds the bookmarks in data that aren't already present and returns how many
+// were added.
+func (fs *FileStore) ImportBookmarks(data string) (int, error) {
+	migrated, _, err := migrate([]byte(data), bookmarksDocs.migrations)
+	if err != nil {
+		return 0, fmt.Errorf("unable to import bookmarks: %w", err)
+	}
+
+	doc := &bookmarksDoc{}
+	if err := yaml.Unmarshal(migrated, doc); err != nil {
+		return 0, fmt.Errorf("unable to unmarshal bookmarks: %w", err)
+	}
+
+	added := 0
+	_, err = fs.modifyBookmarks(func(bms []*Bookmark) ([]*Bookmark, error) {
+		for _, b := range doc.Bookmarks {
+			if err := b.validate(); err != nil {
+				return nil, err
+			}
+			if slices.ContainsFunc(bms, b.sameTarget) {
+				continue
+			}
+			b.Id = uniuri.New()
+			if b.CreatedAt.IsZero() {
+				b.CreatedAt = time.Now()
+			}
+			bms = append(bms, b)
+			added++
+		}
+		return bms, nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return added, nil
+}
@@ -0,0 +1,80 @@
+package store
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBookmarks(t *testing.T) {
+	store := MockFileStore(t)
+
+	bms, err := store.ReadBookmarks()
+	assert.NoError(t, err)
+	assert.Empty(t, bms)
+
+	pod, err := store.AddBookmark(Bookmark{
+		Title: "web", Folder: "shop", K8sContext: "kind", K8sNamespace: "shop", Kind: "Pod", Name: "web-0",
+	})
+	assert.NoError(t, err)
+	assert.NotEmpty(t, pod.Id)
+	assert.False(t, pod.CreatedAt.IsZero())
+
+	_, err = store.AddBookmark(Bookmark{Title: "deploys", K8sContext: "kind", K8sNamespace: "shop", Query: "deploy"})
+	assert.NoError(t, err)
+
+	_, err = store.AddBookmark(Bookmark{Title: "nothing", K8sContext: "kind"})
+	assert.Error(t, err)
+
+	bms, err = store.UpdateBookmark(pod.Id, "web pod", "team/shop")
+	assert.NoError(t, err)
+	assert.Len(t, bms, 2)
+	assert.Equal(t, "web pod", bms[0].Title)
+	assert.Equal(t, []string{"team/shop"}, BookmarkFolders(bms))
+
+	bms, err = store.DeleteBookmark(pod.Id)
+	assert.NoError(t, err)
+	assert.Len(t, bms, 1)
+	assert.Equal(t, "deploys", bms[0].Title)
+}
+
+func TestBookmarkPath(t *testing.T) {
+	pod := Bookmark{K8sContext: "kind", K8sNamespace: "shop", Group: "apps", Kind: "Deployment", Name: "web"}
+	assert.Equal(t, "/resource?group=apps&k8sCtx=kind&k8sNs=shop&kind=Deployment&name=web", pod.Path())
+
+	query := Bookmark{K8sContext: "kind", K8sNamespace: "shop", Query: "deploy,svc"}
+	assert.Equal(t, "/resources?k8sCtx=kind&k8sNs=shop&query=deploy%2Csvc", query.Path())
+}
+
+func TestExportImportBookmarks(t *testing.T) {
+	from := MockFileStore(t)
+	_, err := from.AddBookmark(Bookmark{Title: "web", Folder: "shop", K8sContext: "kind", K8sNamespace: "shop", Kind: "Pod", Name: "web-0"})
+	assert.NoError(t, err)
+	_, err = from.AddBookmark(Bookmark{Title: "nodes", Folder: "infra", K8sContext: "kind", Query: "nodes"})
+	assert.NoError(t, err)
+
+	exported, err := from.ExportBookmarks("shop")
+	assert.NoError(t, err)
+	assert.Contains(t, exported, "version: 1")
+	assert.NotContains(t, exported, "nodes")
+	assert.NotContains(t, exported, "id:")
+
+	to := MockFileStore(t)
+	added, err := to.ImportBookmarks(exported)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, added)
+
+	// Importing again doesn't duplicate
+	added, err = to.ImportBookmarks(exported)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, added)
+
+	bms, err := to.ReadBookmarks()
+	assert.NoError(t, err)
+	assert.Len(t, bms, 1)
+	assert.Equal(t, "web-0", bms[0].Name)
+	assert.NotEmpty(t, bms[0].Id)
+
+	_, err = to.ImportBookmarks("bookmarks: [")
+	assert.Error(t, err)
+}
@@ -0,0 +1,128 @@
+package store
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+// files is where a FileStore keeps its files: the file system, or memory when the file system
+// can't be used.
+type files interface {
+	ReadFile(name string) ([]byte, error)
+	// WriteFile replaces name so readers see either the old or the new content
+	WriteFile(name string, data []byte) error
+	// ReadDir lists the names of the files in dir
+	ReadDir(dir string) ([]string, error)
+	Rename(from string, to string) error
+	Remove(name string) error
+	Exists(name string) bool
+}
+
+type osFiles struct{}
+
+func (osFiles) ReadFile(name string) ([]byte, error) {
+	return os.ReadFile(name)
+}
+
+func (osFiles) WriteFile(name string, data []byte) error {
+	return writeFileAtomic(name, data)
+}
+
+func (osFiles) ReadDir(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(entries))
+	for _, e := range entries {
+		if !e.IsDir() {
+			names = append(names, e.Name())
+		}
+	}
+	return names, nil
+}
+
+func (osFiles) Rename(from string, to string) error {
+	return os.Rename(from, to)
+}
+
+func (osFiles) Remove(name string) error {
+	return os.Remove(name)
+}
+
+func (osFiles) Exists(name string) bool {
+	_, err := os.Stat(name)
+	return err == nil
+}
+
+// memFiles lose everything when bosun exits.
+type memFiles struct {
+	lock  sync.Mutex
+	files map[string][]byte
+}
+
+func newMemFiles() *memFiles {
+	return &memFiles{files: map[string][]byte{}}
+}
+
+func (m *memFiles) ReadFile(name string) ([]byte, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	data, found := m.files[name]
+	if !found {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	return append([]byte(nil), data...), nil
+}
+
+func (m *memFiles) WriteFile(name string, data []byte) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.files[name] = append([]byte(nil), data...)
+	return nil
+}
+
+func (m *memFiles) ReadDir(dir string) ([]string, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	names := make([]string, 0)
+	for name := range m.files {
+		if filepath.Dir(name) == filepath.Clean(dir) {
+			names = append(names, filepath.Base(name))
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+func (m *memFiles) Rename(from string, to string) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	data, found := m.files[from]
+	if !found {
+		return &fs.PathError{Op: "rename", Path: from, Err: fs.ErrNotExist}
+	}
+	delete(m.files, from)
+	m.files[to] = data
+	return nil
+}
+
+func (m *memFiles) Remove(name string) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if _, found := m.files[name]; !found {
+		return &fs.PathError{Op: "remove", Path: name, Err: fs.ErrNotExist}
+	}
+	delete(m.files, name)
+	return nil
+}
+
+func (m *memFiles) Exists(name string) bool {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	_, found := m.files[name]
+	return found
+}
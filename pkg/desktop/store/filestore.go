@@ -1,76 +1,320 @@
 package store
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
 
 	"bosun/pkg/desktop/tabs"
+	"bosun/pkg/kube"
+	"bosun/pkg/kube/relations"
+	"bosun/pkg/util"
 
 	"github.com/adrg/xdg"
-	"github.com/goccy/go-yaml"
 )
 
 const (
-	APP_DIR  = "bosun"
-	TAB_FILE = "tabs.yml"
+	APP_DIR        = "bosun"
+	TAB_FILE       = "tabs.yml"
+	RELATIONS_FILE = "relations.yml"
+	WORKSPACES_DIR = "workspaces"
+	BOOKMARKS_FILE = "bookmarks.yml"
+	RECENT_FILE    = "recent.yml"
+	QUERIES_FILE   = "queries.yml"
+	COLUMNS_FILE   = "columns.yml"
+	YAML_EXT       = ".yml"
+	// The tabs that aren't a named workspace, kept while a workspace is open. Workspace names
+	// can't start with a dot.
+	UNNAMED_WORKSPACE_FILE = ".unnamed" + YAML_EXT
 )
 
 type FileStore struct {
-	tabsFile string
+	// Guards read, modify, write of the files below. Tabs are guarded by their owner.
+	lock          sync.Mutex
+	files         files
+	tabsFile      string
+	relationsFile string
+	columnsFile   string
+	workspacesDir string
+	bookmarksFile string
+	recentFile    string
+	queriesFile   string
 }
 
+// MakeFileStore keeps user data in the XDG state dir and reads configuration from the XDG config
+// dir. Files from older versions of bosun are moved and migrated first.
 func MakeFileStore() (*FileStore, error) {
-	tf, err := cacheFile(TAB_FILE)
+	tf, err := stateFile(TAB_FILE)
 	if err != nil {
 		return nil, fmt.Errorf("tabsfile error: %w", err)
 	}
 
+	rf, err := configFile(RELATIONS_FILE)
+	if err != nil {
+		return nil, fmt.Errorf("relationsfile error: %w", err)
+	}
+
+	cf, err := configFile(COLUMNS_FILE)
+	if err != nil {
+		return nil, fmt.Errorf("columnsfile error: %w", err)
+	}
+
+	wd, err := stateDir(WORKSPACES_DIR)
+	if err != nil {
+		return nil, fmt.Errorf("workspaces dir error: %w", err)
+	}
+
+	bf, err := stateFile(BOOKMARKS_FILE)
+	if err != nil {
+		return nil, fmt.Errorf("bookmarksfile error: %w", err)
+	}
+
+	rcf, err := stateFile(RECENT_FILE)
+	if err != nil {
+		return nil, fmt.Errorf("recentfile error: %w", err)
+	}
+
+	qf, err := stateFile(QUERIES_FILE)
+	if err != nil {
+		return nil, fmt.Errorf("queriesfile error: %w", err)
+	}
+
+	fs := &FileStore{
+		files:         osFiles{},
+		tabsFile:      tf,
+		relationsFile: rf,
+		columnsFile:   cf,
+		workspacesDir: wd,
+		bookmarksFile: bf,
+		recentFile:    rcf,
+		queriesFile:   qf,
+	}
+
+	// A file that can't be migrated is left alone. Reading it will report the problem.
+	for _, err := range fs.migrate() {
+		log.Error("unable to migrate", "error", err)
+	}
+
+	return fs, nil
+}
+
+// MakeMemoryFileStore is a fallback when MakeFileStore fails. Nothing survives a restart.
+func MakeMemoryFileStore() *FileStore {
+	dir := filepath.Join(string(filepath.Separator), APP_DIR)
 	return &FileStore{
-		tabsFile: tf,
-	}, nil
+		files:         newMemFiles(),
+		tabsFile:      filepath.Join(dir, TAB_FILE),
+		relationsFile: filepath.Join(dir, RELATIONS_FILE),
+		columnsFile:   filepath.Join(dir, COLUMNS_FILE),
+		workspacesDir: filepath.Join(dir, WORKSPACES_DIR),
+		bookmarksFile: filepath.Join(dir, BOOKMARKS_FILE),
+		recentFile:    filepath.Join(dir, RECENT_FILE),
+		queriesFile:   filepath.Join(dir, QUERIES_FILE),
+	}
 }
 
 func CacheTabs(tabs tabs.Tabs) error {
 	return nil
 }
 
+// ReadTabs falls back to the backup written by WriteTabs when the tabs file is corrupt. The
+// restored tabs are returned along with an error describing the problem. A tabs file from a newer
+// version of bosun is moved aside so writing tabs, or their backup, doesn't replace it.
 func (fs *FileStore) ReadTabs() (*tabs.Tabs, bool, error) {
-	// read fs.tabsFile
-	data, err := os.ReadFile(fs.tabsFile)
-	if err != nil {
-		return nil, false, fmt.Errorf("unable to read tabs file %s: %w", fs.tabsFile, err)
+	ts, found, err := readTabsFile(fs.files, fs.tabsFile)
+	var newer *newerVersionError
+	if errors.As(err, &newer) {
+		aside := fs.tabsFile + NEWER_EXT
+		if renameErr := fs.files.Rename(fs.tabsFile, aside); renameErr != nil {
+			return nil, false, fmt.Errorf("%w, and unable to move it aside: %w", err, renameErr)
+		}
+		return nil, false, fmt.Errorf("%w, moved it to %s", err, aside)
+	}
+	if err != nil && !errors.Is(err, errCorrupt) {
+		return nil, false, err
+	}
+	if err == nil && found {
+		return ts, true, nil
 	}
 
-	content := string(data)
-	if content == "" {
+	// Corrupt or empty. Use the backup if there is one.
+	if err == nil {
+		err = fmt.Errorf("tabs file %s is empty", fs.tabsFile)
+	}
+	backup, backupFound, backupErr := readTabsFile(fs.files, backupFile(fs.tabsFile))
+	if backupErr != nil || !backupFound {
+		if errors.Is(err, errCorrupt) {
+			return nil, false, err
+		}
 		return nil, false, nil
 	}
 
-	ts := &(tabs.Tabs{})
-	if err := yaml.Unmarshal([]byte(content), ts); err != nil {
-		return nil, false, fmt.Errorf("unable to unmarshal tabs: %w", err)
+	if err := fs.files.Rename(fs.tabsFile, fs.tabsFile+CORRUPT_EXT); err != nil {
+		log.Error("unable to set aside corrupt tabs file", "file", fs.tabsFile, "error", err)
+	}
+	if err := writeTabsFile(fs.files, fs.tabsFile, backup); err != nil {
+		log.Error("unable to restore tabs file from backup", "file", fs.tabsFile, "error", err)
 	}
 
-	return ts, true, nil
+	return backup, true, fmt.Errorf("restored tabs from backup %s: %w", backupFile(fs.tabsFile), err)
 }
 
 func (fs *FileStore) WriteTabs(t *tabs.Tabs) error {
-	bs, err := yaml.Marshal(t)
+	return writeTabsFile(fs.files, fs.tabsFile, t)
+}
+
+// ListWorkspaces returns the names of the saved workspaces, sorted.
+func (fs *FileStore) ListWorkspaces() ([]string, error) {
+	entries, err := fs.files.ReadDir(fs.workspacesDir)
+	if err != nil {
+		return nil, fmt.Errorf("unable to list workspaces %s: %w", fs.workspacesDir, err)
+	}
+
+	names := make([]string, 0, len(entries))
+	for _, e := range entries {
+		if strings.HasSuffix(e, YAML_EXT) && !strings.HasPrefix(e, ".") {
+			names = append(names, strings.TrimSuffix(e, YAML_EXT))
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+func (fs *FileStore) ReadWorkspace(name string) (*tabs.Tabs, bool, error) {
+	file, err := fs.workspaceFile(name)
 	if err != nil {
-		return fmt.Errorf("unable to marshal tabs: %w", err)
+		return nil, false, err
 	}
 
-	err = os.WriteFile(fs.tabsFile, bs, 0644)
+	ts, found, err := readTabsFile(fs.files, file)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, false, nil
+	}
 	if err != nil {
-		return fmt.Errorf("unable to write tabs: %w", err)
+		return nil, false, fmt.Errorf("unable to read workspace %s: %w", name, err)
 	}
+	return ts, found, nil
+}
+
+func (fs *FileStore) WriteWorkspace(name string, t *tabs.Tabs) error {
+	file, err := fs.workspaceFile(name)
+	if err != nil {
+		return err
+	}
+	return writeTabsFile(fs.files, file, t)
+}
 
+// ReadUnnamedWorkspace reads the tabs saved by WriteUnnamedWorkspace.
+func (fs *FileStore) ReadUnnamedWorkspace() (*tabs.Tabs, bool, error) {
+	ts, found, err := readTabsFile(fs.files, filepath.Join(fs.workspacesDir, UNNAMED_WORKSPACE_FILE))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("unable to read the unnamed workspace: %w", err)
+	}
+	return ts, found, nil
+}
+
+// WriteUnnamedWorkspace saves the tabs that aren't a named workspace before another is opened.
+func (fs *FileStore) WriteUnnamedWorkspace(t *tabs.Tabs) error {
+	return writeTabsFile(fs.files, filepath.Join(fs.workspacesDir, UNNAMED_WORKSPACE_FILE), t)
+}
+
+func (fs *FileStore) RenameWorkspace(oldName string, newName string) error {
+	oldFile, err := fs.workspaceFile(oldName)
+	if err != nil {
+		return err
+	}
+	newFile, err := fs.workspaceFile(newName)
+	if err != nil {
+		return err
+	}
+
+	if fs.files.Exists(newFile) {
+		return fmt.Errorf("workspace %s already exists", newName)
+	}
+	if err := fs.files.Rename(oldFile, newFile); err != nil {
+		return fmt.Errorf("unable to rename workspace %s to %s: %w", oldName, newName, err)
+	}
 	return nil
 }
 
-func cacheFile(filename string) (string, error) {
-	file, err := xdg.CacheFile(filepath.Join(APP_DIR, filename))
+func (fs *FileStore) DeleteWorkspace(name string) error {
+	file, err := fs.workspaceFile(name)
+	if err != nil {
+		return err
+	}
+	if err := fs.files.Remove(file); err != nil {
+		return fmt.Errorf("unable to delete workspace %s: %w", name, err)
+	}
+	return nil
+}
+
+func (fs *FileStore) workspaceFile(name string) (string, error) {
+	if name == "" || strings.HasPrefix(name, ".") || strings.ContainsAny(name, `/\:`) {
+		return "", fmt.Errorf("invalid workspace name '%s'", name)
+	}
+	return filepath.Join(fs.workspacesDir, name+YAML_EXT), nil
+}
+
+// ReadRelationRules reads the user's relation rules. Rules that fail validation are dropped and
+// reported in the error alongside the valid ones.
+func (fs *FileStore) ReadRelationRules() ([]relations.Rule, error) {
+	return readConfig(fs.files, fs.relationsFile, relationsMigrations, func(rf *relations.RuleFile) []relations.Rule {
+		return rf.Rules
+	})
+}
+
+// ReadColumnSets reads the user's table columns. Column sets that fail validation are dropped and
+// reported in the error alongside the valid ones.
+func (fs *FileStore) ReadColumnSets() ([]kube.ColumnSet, error) {
+	return readConfig(fs.files, fs.columnsFile, columnsMigrations, func(cf *kube.ColumnFile) []kube.ColumnSet {
+		return cf.ColumnSets
+	})
+}
+
+// readConfig reads the entries of a configuration file, D, the user writes. A missing file has
+// none.
+func readConfig[D any, T interface{ Validate() error }](files files, file string, migrations []migration, entries func(*D) []T) ([]T, error) {
+	doc := new(D)
+	_, err := readVersioned(files, file, migrations, doc)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to read %s: %w", filepath.Base(file), err)
+	}
+
+	valid, errs := util.Valid(entries(doc))
+	if len(errs) > 0 {
+		return valid, fmt.Errorf("invalid entries in %s: %w", file, errors.Join(errs...))
+	}
+	return valid, nil
+}
+
+func stateFile(filename string) (string, error) {
+	file, err := xdg.StateFile(filepath.Join(APP_DIR, filename))
+	if err != nil {
+		return "", fmt.Errorf("unable to construct filename %s: %w", filename, err)
+	}
+	return file, nil
+}
+
+func stateDir(dirname string) (string, error) {
+	dir := filepath.Join(xdg.StateHome, APP_DIR, dirname)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("unable to create dir %s: %w", dir, err)
+	}
+	return dir, nil
+}
+
+func configFile(filename string) (string, error) {
+	file, err := xdg.ConfigFile(filepath.Join(APP_DIR, filename))
 	if err != nil {
 		return "", fmt.Errorf("unable to construct filename %s: %w", filename, err)
 	}
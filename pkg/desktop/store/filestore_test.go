@@ -2,6 +2,7 @@ package store
 
 import (
 	"os"
+	"path/filepath"
 	"testing"
 
 	"bosun/pkg/desktop/tabs"
@@ -14,7 +15,12 @@ func MockFileStore(t *testing.T) *FileStore {
 	assert.NoError(t, err)
 
 	return &FileStore{
-		tabsFile: file.Name(),
+		files:         osFiles{},
+		tabsFile:      file.Name(),
+		workspacesDir: t.TempDir(),
+		bookmarksFile: filepath.Join(t.TempDir(), BOOKMARKS_FILE),
+		recentFile:    filepath.Join(t.TempDir(), RECENT_FILE),
+		queriesFile:   filepath.Join(t.TempDir(), QUERIES_FILE),
 	}
 }
 
@@ -36,3 +42,187 @@ func TestTabs(t *testing.T) {
 	assert.True(t, found)
 	assert.Equal(t, tsRead, ts)
 }
+
+func TestTabsHistory(t *testing.T) {
+	store := MockFileStore(t)
+
+	ts := &tabs.Tabs{}
+	ts.NewTab()
+	ts.Update(ts.Current, "kind", "default", "/kind/default", "default")
+	ts.Update(ts.Current, "", "", "/kind/default/pod/web", "web")
+	ts.GoBack(ts.Current)
+	assert.NoError(t, store.WriteTabs(ts))
+
+	tsRead, found, err := store.ReadTabs()
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, ts, tsRead)
+	assert.Len(t, tsRead.All[0].Forward, 1)
+}
+
+func TestReadRelationRules(t *testing.T) {
+	store := MockFileStore(t)
+	store.relationsFile = filepath.Join(t.TempDir(), "relations.yml")
+
+	rules, err := store.ReadRelationRules()
+	assert.NoError(t, err)
+	assert.Empty(t, rules)
+
+	err = os.WriteFile(store.relationsFile, []byte(`
+rules:
+  - group: platform.example.com
+    kind: Database
+    namePath: .spec.credentialsSecret
+    target:
+      version: v1
+      kind: Secret
+  - group: platform.example.com
+    kind: Database
+    namePath: .spec.credentialsSecret
+`), 0644)
+	assert.NoError(t, err)
+
+	rules, err = store.ReadRelationRules()
+	assert.Error(t, err)
+	assert.Len(t, rules, 1)
+	assert.Equal(t, "Secret", rules[0].Target.Kind)
+}
+
+func TestReadColumnSets(t *testing.T) {
+	store := MockFileStore(t)
+	store.columnsFile = filepath.Join(t.TempDir(), "columns.yml")
+
+	sets, err := store.ReadColumnSets()
+	assert.NoError(t, err)
+	assert.Empty(t, sets)
+
+	err = os.WriteFile(store.columnsFile, []byte(`
+columnSets:
+  - kind: Pod
+    append: true
+    columns:
+      - name: Images
+        jsonPath: .spec.containers[*].image
+      - name: Scrape
+        jsonPath: '{.metadata.annotations.prometheus\.io/scrape}'
+  - kind: Service
+    columns:
+      - name: Broken
+        jsonPath: "{.spec.ports[}"
+`), 0644)
+	assert.NoError(t, err)
+
+	sets, err = store.ReadColumnSets()
+	assert.Error(t, err)
+	assert.Len(t, sets, 1)
+	assert.True(t, sets[0].Append)
+	assert.Equal(t, "Images", sets[0].Columns[0].Name)
+}
+
+func TestWorkspaces(t *testing.T) {
+	store := MockFileStore(t)
+
+	names, err := store.ListWorkspaces()
+	assert.NoError(t, err)
+	assert.Empty(t, names)
+
+	ts := tabs.MakeInitialTabs()
+	assert.NoError(t, store.WriteWorkspace("prod-us-east", ts))
+	assert.NoError(t, store.WriteWorkspace("incident-4821", ts))
+
+	names, err = store.ListWorkspaces()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"incident-4821", "prod-us-east"}, names)
+
+	read, found, err := store.ReadWorkspace("incident-4821")
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, ts, read)
+
+	assert.Error(t, store.RenameWorkspace("incident-4821", "prod-us-east"))
+	assert.NoError(t, store.RenameWorkspace("incident-4821", "incident-4822"))
+	assert.NoError(t, store.DeleteWorkspace("prod-us-east"))
+
+	names, err = store.ListWorkspaces()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"incident-4822"}, names)
+
+	_, found, err = store.ReadWorkspace("prod-us-east")
+	assert.NoError(t, err)
+	assert.False(t, found)
+
+	assert.Error(t, store.WriteWorkspace("../tabs", ts))
+	assert.Error(t, store.WriteWorkspace("", ts))
+}
+
+func TestTabsRestoreBackup(t *testing.T) {
+	store := MockFileStore(t)
+
+	ts := tabs.MakeInitialTabs()
+	ts.Update(ts.Current, "kind", "default", "/kind/default", "first")
+	assert.NoError(t, store.WriteTabs(ts))
+	ts.Update(ts.Current, "", "", "/kind/default/pod/web", "second")
+	assert.NoError(t, store.WriteTabs(ts))
+
+	// Backup holds the previous write
+	backup, found, err := readTabsFile(osFiles{}, backupFile(store.tabsFile))
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, "first", backup.All[0].Title)
+
+	err = os.WriteFile(store.tabsFile, []byte("Current: [\n  not yaml"), 0644)
+	assert.NoError(t, err)
+
+	restored, found, err := store.ReadTabs()
+	assert.Error(t, err)
+	assert.True(t, found)
+	assert.Equal(t, "first", restored.All[0].Title)
+
+	_, err = os.Stat(store.tabsFile + CORRUPT_EXT)
+	assert.NoError(t, err)
+
+	// The restored file reads cleanly
+	_, found, err = store.ReadTabs()
+	assert.NoError(t, err)
+	assert.True(t, found)
+}
+
+func TestTabsCorruptWithoutBackup(t *testing.T) {
+	store := MockFileStore(t)
+	err := os.WriteFile(store.tabsFile, []byte("Current: [\n  not yaml"), 0644)
+	assert.NoError(t, err)
+
+	_, found, err := store.ReadTabs()
+	assert.ErrorIs(t, err, errCorrupt)
+	assert.False(t, found)
+}
+
+func TestMemoryFileStore(t *testing.T) {
+	store := MakeMemoryFileStore()
+
+	_, found, err := store.ReadTabs()
+	assert.ErrorIs(t, err, os.ErrNotExist)
+	assert.False(t, found)
+
+	ts := tabs.MakeInitialTabs()
+	assert.NoError(t, store.WriteTabs(ts))
+	tsRead, found, err := store.ReadTabs()
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, ts, tsRead)
+
+	assert.NoError(t, store.WriteWorkspace("ops", ts))
+	assert.NoError(t, store.RenameWorkspace("ops", "oncall"))
+	names, err := store.ListWorkspaces()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"oncall"}, names)
+	assert.NoError(t, store.DeleteWorkspace("oncall"))
+	assert.Error(t, store.DeleteWorkspace("oncall"))
+
+	rules, err := store.ReadRelationRules()
+	assert.NoError(t, err)
+	assert.Empty(t, rules)
+
+	_, err = os.Stat(store.tabsFile)
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
@@ -0,0 +1,170 @@
+package store
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dchest/uniuri"
+)
+
+// Bound on the query history kept for each context
+const MAX_QUERY_HISTORY = 200
+
+// QueryHistoryEntry counts how often a query was run in a context and namespace.
+type QueryHistoryEntry struct {
+	K8sContext   string    `yaml:"k8sContext"`
+	K8sNamespace string    `yaml:"k8sNamespace,omitempty"`
+	Query        string    `yaml:"query"`
+	Count        int       `yaml:"count"`
+	LastRunAt    time.Time `yaml:"lastRunAt"`
+}
+
+// SavedQuery is a named query. The selectors narrow every kind in the query.
+type SavedQuery struct {
+	Id            string    `yaml:"id"`
+	Name          string    `yaml:"name"`
+	K8sContext    string    `yaml:"k8sContext"`
+	K8sNamespace  string    `yaml:"k8sNamespace,omitempty"`
+	Query         string    `yaml:"query"`
+	LabelSelector string    `yaml:"labelSelector,omitempty"`
+	FieldSelector string    `yaml:"fieldSelector,omitempty"`
+	CreatedAt     time.Time `yaml:"createdAt"`
+}
+
+type queriesDoc struct {
+	Versioned `yaml:",inline"`
+	History   []*QueryHistoryEntry `yaml:"history"`
+	Saved     []*SavedQuery        `yaml:"saved"`
+}
+
+var queriesDocs = docFile[*queriesDoc]{
+	path:       func(fs *FileStore) string { return fs.queriesFile },
+	migrations: []migration{noMigration},
+	empty: func() *queriesDoc {
+		return &queriesDoc{History: []*QueryHistoryEntry{}, Saved: []*SavedQuery{}}
+	},
+}
+
+func (q *SavedQuery) validate() error {
+	if strings.TrimSpace(q.Name) == "" {
+		return fmt.Errorf("saved query needs a name")
+	}
+	if q.K8sContext == "" {
+		return fmt.Errorf("saved query %s has no context", q.Name)
+	}
+	if strings.TrimSpace(q.Query) == "" {
+		return fmt.Errorf("saved query %s has no query", q.Name)
+	}
+	return nil
+}
+
+// RecordQuery counts a run of query in the context and namespace.
+func (fs *FileStore) RecordQuery(k8sCtx string, k8sNs string, query string) error {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil
+	}
+
+	_, err := queriesDocs.modify(fs, func(doc *queriesDoc) error {
+		idx := slices.IndexFunc(doc.History, func(e *QueryHistoryEntry) bool {
+			return e.K8sContext == k8sCtx && e.K8sNamespace == k8sNs && e.Query == query
+		})
+		if idx == -1 {
+			doc.History = append(doc.History, &QueryHistoryEntry{K8sContext: k8sCtx, K8sNamespace: k8sNs, Query: query})
+			idx = len(doc.History) - 1
+		}
+		doc.History[idx].Count++
+		doc.History[idx].LastRunAt = time.Now()
+
+		// Drop the least recently run beyond MAX_QUERY_HISTORY for this context
+		sort.SliceStable(doc.History, func(i, j int) bool {
+			return doc.History[i].LastRunAt.After(doc.History[j].LastRunAt)
+		})
+		inCtx := 0
+		doc.History = slices.DeleteFunc(doc.History, func(e *QueryHistoryEntry) bool {
+			if e.K8sContext != k8sCtx {
+				return false
+			}
+			inCtx++
+			return inCtx > MAX_QUERY_HISTORY
+		})
+		return nil
+	})
+	return err
+}
+
+// QueryHistory returns the queries run in the context and namespace, most frequent first, then
+// most recent.
+func (fs *FileStore) QueryHistory(k8sCtx string, k8sNs string, limit int) ([]*QueryHistoryEntry, error) {
+	fs.lock.Lock()
+	doc, err := queriesDocs.read(fs)
+	fs.lock.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	history := slices.DeleteFunc(doc.History, func(e *QueryHistoryEntry) bool {
+		return e.K8sContext != k8sCtx || e.K8sNamespace != k8sNs
+	})
+	sort.SliceStable(history, func(i, j int) bool {
+		if history[i].Count != history[j].Count {
+			return history[i].Count > history[j].Count
+		}
+		return history[i].LastRunAt.After(history[j].LastRunAt)
+	})
+	if len(history) > limit {
+		history = history[:limit]
+	}
+	return history, nil
+}
+
+func (fs *FileStore) SavedQueries() ([]*SavedQuery, error) {
+	fs.lock.Lock()
+	defer fs.lock.Unlock()
+
+	doc, err := queriesDocs.read(fs)
+	if err != nil {
+		return nil, err
+	}
+	return doc.Saved, nil
+}
+
+// SaveQuery adds q, or replaces the saved query with the same name in the same context.
+func (fs *FileStore) SaveQuery(q SavedQuery) (*SavedQuery, error) {
+	if err := q.validate(); err != nil {
+		return nil, err
+	}
+	q.Id = uniuri.New()
+	q.CreatedAt = time.Now()
+
+	_, err := queriesDocs.modify(fs, func(doc *queriesDoc) error {
+		idx := slices.IndexFunc(doc.Saved, func(s *SavedQuery) bool {
+			return s.K8sContext == q.K8sContext && s.Name == q.Name
+		})
+		if idx == -1 {
+			doc.Saved = append(doc.Saved, &q)
+		} else {
+			q.Id = doc.Saved[idx].Id
+			doc.Saved[idx] = &q
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &q, nil
+}
+
+func (fs *FileStore) DeleteSavedQuery(id string) ([]*SavedQuery, error) {
+	doc, err := queriesDocs.modify(fs, func(doc *queriesDoc) error {
+		doc.Saved = slices.DeleteFunc(doc.Saved, func(s *SavedQuery) bool { return s.Id == id })
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return doc.Saved, nil
+}
@@ -0,0 +1,73 @@
+package store
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueryHistory(t *testing.T) {
+	store := MockFileStore(t)
+
+	assert.NoError(t, store.RecordQuery("kind", "shop", "po"))
+	assert.NoError(t, store.RecordQuery("kind", "shop", "deploy,svc"))
+	assert.NoError(t, store.RecordQuery("kind", "shop", "deploy,svc"))
+	assert.NoError(t, store.RecordQuery("kind", "shop", "cm"))
+	assert.NoError(t, store.RecordQuery("kind", "other", "po"))
+	assert.NoError(t, store.RecordQuery("kind", "shop", " "))
+
+	history, err := store.QueryHistory("kind", "shop", 10)
+	assert.NoError(t, err)
+	// Most frequent, then most recent
+	assert.Equal(t, []string{"deploy,svc", "cm", "po"}, historyQueries(history))
+	assert.Equal(t, 2, history[0].Count)
+
+	history, err = store.QueryHistory("kind", "shop", 1)
+	assert.NoError(t, err)
+	assert.Len(t, history, 1)
+
+	history, err = store.QueryHistory("prod", "shop", 10)
+	assert.NoError(t, err)
+	assert.Empty(t, history)
+}
+
+func TestSavedQueries(t *testing.T) {
+	store := MockFileStore(t)
+
+	saved, err := store.SavedQueries()
+	assert.NoError(t, err)
+	assert.Empty(t, saved)
+
+	web, err := store.SaveQuery(SavedQuery{Name: "web", K8sContext: "kind", K8sNamespace: "shop", Query: "deploy,svc", LabelSelector: "app=web"})
+	assert.NoError(t, err)
+	assert.NotEmpty(t, web.Id)
+
+	_, err = store.SaveQuery(SavedQuery{Name: "failing", K8sContext: "kind", Query: "po", FieldSelector: "status.phase=Failed"})
+	assert.NoError(t, err)
+
+	// Same name replaces, keeping the id
+	replaced, err := store.SaveQuery(SavedQuery{Name: "web", K8sContext: "kind", K8sNamespace: "shop", Query: "deploy", LabelSelector: "app=web"})
+	assert.NoError(t, err)
+	assert.Equal(t, web.Id, replaced.Id)
+
+	saved, err = store.SavedQueries()
+	assert.NoError(t, err)
+	assert.Len(t, saved, 2)
+	assert.Equal(t, "deploy", saved[0].Query)
+
+	_, err = store.SaveQuery(SavedQuery{Name: "", K8sContext: "kind", Query: "po"})
+	assert.Error(t, err)
+
+	saved, err = store.DeleteSavedQuery(web.Id)
+	assert.NoError(t, err)
+	assert.Len(t, saved, 1)
+	assert.Equal(t, "failing", saved[0].Name)
+}
+
+func historyQueries(history []*QueryHistoryEntry) []string {
+	qs := make([]string, len(history))
+	for i, h := range history {
+		qs[i] = h.Query
+	}
+	return qs
+}
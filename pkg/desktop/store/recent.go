@@ -0,0 +1,137 @@
+package store
+
+import (
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Bound on the recently viewed resources kept for each context
+const MAX_RECENT = 100
+
+type RecentResource struct {
+	K8sContext   string    `yaml:"k8sContext"`
+	K8sNamespace string    `yaml:"k8sNamespace,omitempty"`
+	Group        string    `yaml:"group,omitempty"`
+	Kind         string    `yaml:"kind"`
+	Name         string    `yaml:"name"`
+	ViewedAt     time.Time `yaml:"viewedAt"`
+	Path         string    `yaml:"-"`
+}
+
+type recentDoc struct {
+	Versioned `yaml:",inline"`
+	Recent    []*RecentResource `yaml:"recent"`
+}
+
+var recentDocs = docFile[*recentDoc]{
+	path:       func(fs *FileStore) string { return fs.recentFile },
+	migrations: []migration{noMigration},
+	empty:      func() *recentDoc { return &recentDoc{Recent: []*RecentResource{}} },
+}
+
+func (r *RecentResource) sameResource(o *RecentResource) bool {
+	return r.K8sContext == o.K8sContext &&
+		r.K8sNamespace == o.K8sNamespace &&
+		r.Group == o.Group &&
+		r.Kind == o.Kind &&
+		r.Name == o.Name
+}
+
+// RecordRecent moves the resource to the front of its context's most recently used list.
+func (fs *FileStore) RecordRecent(k8sCtx string, k8sNs string, group string, kind string, name string) error {
+	viewed := &RecentResource{
+		K8sContext:   k8sCtx,
+		K8sNamespace: k8sNs,
+		Group:        group,
+		Kind:         kind,
+		Name:         name,
+		ViewedAt:     time.Now(),
+	}
+	_, err := recentDocs.modify(fs, func(doc *recentDoc) error {
+		recent := slices.DeleteFunc(doc.Recent, viewed.sameResource)
+		recent = append([]*RecentResource{viewed}, recent...)
+
+		// Drop the oldest beyond MAX_RECENT for this context
+		inCtx := 0
+		doc.Recent = slices.DeleteFunc(recent, func(r *RecentResource) bool {
+			if r.K8sContext != k8sCtx {
+				return false
+			}
+			inCtx++
+			return inCtx > MAX_RECENT
+		})
+		return nil
+	})
+	return err
+}
+
+// SearchRecent finds recently viewed resources in k8sCtx, or every context when it's empty. Every
+// word of query must appear in the kind, namespace or name. Better matches come first, then the
+// most recent.
+func (fs *FileStore) SearchRecent(k8sCtx string, query string, limit int) ([]*RecentResource, error) {
+	fs.lock.Lock()
+	doc, err := recentDocs.read(fs)
+	fs.lock.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	recent := doc.Recent
+
+	words := strings.Fields(strings.ToLower(query))
+	type scored struct {
+		r     *RecentResource
+		score int
+		order int
+	}
+	matches := make([]scored, 0)
+	for i, r := range recent {
+		if k8sCtx != "" && r.K8sContext != k8sCtx {
+			continue
+		}
+		if score, ok := recentScore(r, words); ok {
+			matches = append(matches, scored{r: r, score: score, order: i})
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].score != matches[j].score {
+			return matches[i].score > matches[j].score
+		}
+		return matches[i].order < matches[j].order
+	})
+
+	results := make([]*RecentResource, 0, min(limit, len(matches)))
+	for _, m := range matches {
+		if len(results) >= limit {
+			break
+		}
+		m.r.Path = resourcePath(m.r.K8sContext, m.r.K8sNamespace, m.r.Group, m.r.Kind, m.r.Name)
+		results = append(results, m.r)
+	}
+	return results, nil
+}
+
+// recentScore is 0 for no query. Each word scores more for matching the start of the name.
+func recentScore(r *RecentResource, words []string) (int, bool) {
+	name := strings.ToLower(r.Name)
+	haystack := strings.ToLower(r.Kind + " " + r.K8sNamespace + "/" + r.Name)
+
+	score := 0
+	for _, w := range words {
+		switch {
+		case name == w:
+			score += 4
+		case strings.HasPrefix(name, w):
+			score += 3
+		case strings.Contains(name, w):
+			score += 2
+		case strings.Contains(haystack, w):
+			score += 1
+		default:
+			return 0, false
+		}
+	}
+	return score, true
+}
@@ -0,0 +1,67 @@
+package store
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecent(t *testing.T) {
+	store := MockFileStore(t)
+
+	assert.NoError(t, store.RecordRecent("kind", "shop", "", "Pod", "web-0"))
+	assert.NoError(t, store.RecordRecent("kind", "shop", "apps", "Deployment", "web"))
+	assert.NoError(t, store.RecordRecent("prod", "shop", "apps", "Deployment", "web"))
+	assert.NoError(t, store.RecordRecent("kind", "shop", "", "Service", "orders-web"))
+	// Viewing again moves it to the front without duplicating
+	assert.NoError(t, store.RecordRecent("kind", "shop", "", "Pod", "web-0"))
+
+	recent, err := store.SearchRecent("kind", "", 10)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"web-0", "orders-web", "web"}, recentNames(recent))
+	assert.Equal(t, "/resource?group=&k8sCtx=kind&k8sNs=shop&kind=Pod&name=web-0", recent[0].Path)
+
+	recent, err = store.SearchRecent("", "", 10)
+	assert.NoError(t, err)
+	assert.Len(t, recent, 4)
+
+	// Exact name first, then prefix, then substring
+	recent, err = store.SearchRecent("kind", "web", 10)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"web", "web-0", "orders-web"}, recentNames(recent))
+
+	recent, err = store.SearchRecent("kind", "deploy web", 10)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"web"}, recentNames(recent))
+
+	recent, err = store.SearchRecent("kind", "web", 1)
+	assert.NoError(t, err)
+	assert.Len(t, recent, 1)
+}
+
+func TestRecentBounded(t *testing.T) {
+	store := MockFileStore(t)
+	assert.NoError(t, store.RecordRecent("prod", "shop", "", "Pod", "keep-me"))
+
+	for i := 0; i < MAX_RECENT+5; i++ {
+		assert.NoError(t, store.RecordRecent("kind", "shop", "", "Pod", fmt.Sprintf("pod-%d", i)))
+	}
+
+	recent, err := store.SearchRecent("kind", "", MAX_RECENT*2)
+	assert.NoError(t, err)
+	assert.Len(t, recent, MAX_RECENT)
+	assert.Equal(t, fmt.Sprintf("pod-%d", MAX_RECENT+4), recent[0].Name)
+
+	recent, err = store.SearchRecent("prod", "", 10)
+	assert.NoError(t, err)
+	assert.Len(t, recent, 1)
+}
+
+func recentNames(recent []*RecentResource) []string {
+	names := make([]string, len(recent))
+	for i, r := range recent {
+		names[i] = r.Name
+	}
+	return names
+}
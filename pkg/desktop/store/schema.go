@@ -0,0 +1,260 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"bosun/pkg/desktop/tabs"
+
+	"github.com/adrg/xdg"
+	"github.com/goccy/go-yaml"
+)
+
+// migration upgrades a document from version i to i+1, where i is its index in a migrations list.
+type migration func(doc map[string]interface{}) error
+
+// noMigration is a version that only adds the version field, or the first version of a file that
+// was versioned from the start.
+func noMigration(doc map[string]interface{}) error { return nil }
+
+// Versioned is the version field of a stored document
+type Versioned struct {
+	Version int `yaml:"version"`
+}
+
+func (v *Versioned) setVersion(version int) {
+	v.Version = version
+}
+
+type storedDoc interface {
+	setVersion(version int)
+}
+
+// docFile is a file of one document, D, that's read and modified under the store's lock.
+type docFile[D storedDoc] struct {
+	path       func(fs *FileStore) string
+	migrations []migration
+	// The document of a missing or empty file
+	empty func() D
+}
+
+func (df docFile[D]) version() int {
+	return len(df.migrations)
+}
+
+func (df docFile[D]) migrate(fs *FileStore) error {
+	return migrateFile(fs.files, df.path(fs), df.migrations)
+}
+
+// read is the document at its latest version. The caller holds the store's lock.
+func (df docFile[D]) read(fs *FileStore) (D, error) {
+	doc := df.empty()
+	_, err := readVersioned(fs.files, df.path(fs), df.migrations, doc)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		var none D
+		return none, err
+	}
+	return doc, nil
+}
+
+// modify is a read, modify, write of the document under the store's lock.
+func (df docFile[D]) modify(fs *FileStore, f func(doc D) error) (D, error) {
+	fs.lock.Lock()
+	defer fs.lock.Unlock()
+
+	var none D
+	doc, err := df.read(fs)
+	if err != nil {
+		return none, err
+	}
+	if err := f(doc); err != nil {
+		return none, err
+	}
+
+	doc.setVersion(df.version())
+	return doc, writeVersioned(fs.files, df.path(fs), doc)
+}
+
+// Files of tabs: tabs.yml and workspaces. The current version is len(tabsMigrations).
+var tabsMigrations = []migration{
+	// 0 -> 1: files written before versioning, in the cache dir. Only the version field is new.
+	noMigration,
+}
+
+var TABS_SCHEMA_VERSION = len(tabsMigrations)
+
+// Configuration the user writes: relations.yml and columns.yml. They're migrated as they're read
+// and never rewritten, which would lose the user's comments and formatting.
+var relationsMigrations = []migration{
+	// 0 -> 1: files written before versioning. Only the version field is new.
+	noMigration,
+}
+
+var columnsMigrations = []migration{
+	// 0 -> 1: files written before versioning. Only the version field is new.
+	noMigration,
+}
+
+// tabsDoc is the on disk format of a Tabs
+type tabsDoc struct {
+	Version   int `yaml:"version"`
+	tabs.Tabs `yaml:",inline"`
+}
+
+// migrate upgrades data to the latest version. It reports the version data started at.
+func migrate(data []byte, migrations []migration) ([]byte, int, error) {
+	doc := map[string]interface{}{}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, 0, fmt.Errorf("%w: %w", errCorrupt, err)
+	}
+
+	version, err := docVersion(doc)
+	if err != nil {
+		return nil, 0, err
+	}
+	if version > len(migrations) {
+		return nil, version, &newerVersionError{version: version, supported: len(migrations)}
+	}
+	if version == len(migrations) {
+		return data, version, nil
+	}
+
+	for v := version; v < len(migrations); v++ {
+		if err := migrations[v](doc); err != nil {
+			return nil, version, fmt.Errorf("unable to migrate from version %d: %w", v, err)
+		}
+	}
+	doc["version"] = len(migrations)
+
+	migrated, err := yaml.Marshal(doc)
+	if err != nil {
+		return nil, version, fmt.Errorf("unable to marshal migrated document: %w", err)
+	}
+	return migrated, version, nil
+}
+
+// newerVersionError is a file written by a newer version of bosun.
+type newerVersionError struct {
+	version   int
+	supported int
+}
+
+func (e *newerVersionError) Error() string {
+	return fmt.Sprintf("schema version %d is newer than this version of bosun supports, %d", e.version, e.supported)
+}
+
+func docVersion(doc map[string]interface{}) (int, error) {
+	switch v := doc["version"].(type) {
+	case nil:
+		return 0, nil
+	case int:
+		return v, nil
+	case int64:
+		return int(v), nil
+	case uint64:
+		return int(v), nil
+	default:
+		return 0, fmt.Errorf("%w: unexpected version %v", errCorrupt, v)
+	}
+}
+
+// migrateFile upgrades file in place, keeping a copy of the old version next to it.
+func migrateFile(files files, file string, migrations []migration) error {
+	data, err := files.ReadFile(file)
+	if errors.Is(err, os.ErrNotExist) || (err == nil && len(data) == 0) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("unable to read %s: %w", file, err)
+	}
+
+	migrated, version, err := migrate(data, migrations)
+	if err != nil {
+		return fmt.Errorf("unable to migrate %s: %w", file, err)
+	}
+	if version == len(migrations) {
+		return nil
+	}
+
+	if err := files.WriteFile(fmt.Sprintf("%s.v%d", file, version), data); err != nil {
+		return err
+	}
+	return files.WriteFile(file, migrated)
+}
+
+// migrate moves files from the locations used by older versions of bosun and upgrades every
+// stored file to the current schema version.
+func (fs *FileStore) migrate() []error {
+	errs := make([]error, 0)
+
+	// Before versioning, tabs lived in the cache dir, which cleanup tools may delete.
+	legacyDir := filepath.Join(xdg.CacheHome, APP_DIR)
+	if err := moveIfAbsent(filepath.Join(legacyDir, TAB_FILE), fs.tabsFile); err != nil {
+		errs = append(errs, err)
+	}
+	legacyWorkspaces := filepath.Join(legacyDir, WORKSPACES_DIR)
+	if entries, err := os.ReadDir(legacyWorkspaces); err == nil {
+		for _, e := range entries {
+			if strings.HasSuffix(e.Name(), YAML_EXT) {
+				err := moveIfAbsent(filepath.Join(legacyWorkspaces, e.Name()), filepath.Join(fs.workspacesDir, e.Name()))
+				if err != nil {
+					errs = append(errs, err)
+				}
+			}
+		}
+	}
+
+	tabsFiles := []string{fs.tabsFile}
+	if names, err := fs.ListWorkspaces(); err == nil {
+		for _, name := range names {
+			tabsFiles = append(tabsFiles, filepath.Join(fs.workspacesDir, name+YAML_EXT))
+		}
+	} else {
+		errs = append(errs, err)
+	}
+
+	for _, f := range tabsFiles {
+		if err := migrateFile(fs.files, f, tabsMigrations); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if err := bookmarksDocs.migrate(fs); err != nil {
+		errs = append(errs, err)
+	}
+	if err := recentDocs.migrate(fs); err != nil {
+		errs = append(errs, err)
+	}
+	if err := queriesDocs.migrate(fs); err != nil {
+		errs = append(errs, err)
+	}
+
+	return errs
+}
+
+// moveIfAbsent moves from to to unless to already exists. Renames can't cross file systems so this
+// copies then removes.
+func moveIfAbsent(from string, to string) error {
+	data, err := os.ReadFile(from)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("unable to read %s: %w", from, err)
+	}
+
+	if _, err := os.Stat(to); err == nil {
+		return nil
+	}
+
+	if err := writeFileAtomic(to, data); err != nil {
+		return err
+	}
+	if err := os.Remove(from); err != nil {
+		return fmt.Errorf("unable to remove %s: %w", from, err)
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+package store
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"bosun/pkg/desktop/tabs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const legacyTabs = `current: one
+all:
+- id: one
+  k8scontext: kind
+  k8snamespace: default
+  path: /kind/default
+  title: default
+`
+
+func TestMigrateLegacyTabs(t *testing.T) {
+	file := filepath.Join(t.TempDir(), TAB_FILE)
+	assert.NoError(t, os.WriteFile(file, []byte(legacyTabs), 0644))
+
+	assert.NoError(t, migrateFile(osFiles{}, file, tabsMigrations))
+
+	original, err := os.ReadFile(file + ".v0")
+	assert.NoError(t, err)
+	assert.Equal(t, legacyTabs, string(original))
+
+	migrated, err := os.ReadFile(file)
+	assert.NoError(t, err)
+	assert.Contains(t, string(migrated), "version: 1")
+
+	ts, found, err := readTabsFile(osFiles{}, file)
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, &tabs.Tabs{
+		Current: "one",
+		All: []*tabs.Tab{
+			{Id: "one", K8sContext: "kind", K8sNamespace: "default", Path: "/kind/default", Title: "default"},
+		},
+	}, ts)
+
+	// Already current, nothing to do
+	assert.NoError(t, migrateFile(osFiles{}, file, tabsMigrations))
+	_, err = os.Stat(file + ".v1")
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestMigrateNewerVersion(t *testing.T) {
+	file := filepath.Join(t.TempDir(), TAB_FILE)
+	assert.NoError(t, os.WriteFile(file, []byte("version: 99\ncurrent: one\n"), 0644))
+
+	assert.Error(t, migrateFile(osFiles{}, file, tabsMigrations))
+	_, _, err := readTabsFile(osFiles{}, file)
+	assert.Error(t, err)
+}
+
+func TestReadTabsNewerVersion(t *testing.T) {
+	store := MockFileStore(t)
+	newer := "version: 99\ncurrent: one\n"
+	assert.NoError(t, os.WriteFile(store.tabsFile, []byte(newer), 0644))
+
+	_, found, err := store.ReadTabs()
+	assert.ErrorContains(t, err, "schema version 99 is newer")
+	assert.False(t, found)
+
+	// The app starts with fresh tabs and writes them more than once
+	assert.NoError(t, store.WriteTabs(tabs.MakeInitialTabs()))
+	assert.NoError(t, store.WriteTabs(tabs.MakeInitialTabs()))
+
+	data, err := os.ReadFile(store.tabsFile + NEWER_EXT)
+	assert.NoError(t, err)
+	assert.Equal(t, newer, string(data))
+}
+
+func TestWriteTabsVersion(t *testing.T) {
+	store := MockFileStore(t)
+	assert.NoError(t, store.WriteTabs(tabs.MakeInitialTabs()))
+
+	data, err := os.ReadFile(store.tabsFile)
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), "version: 1\n")
+}
+
+func TestMoveIfAbsent(t *testing.T) {
+	dir := t.TempDir()
+	from := filepath.Join(dir, "from.yml")
+	to := filepath.Join(dir, "to.yml")
+
+	assert.NoError(t, moveIfAbsent(from, to))
+
+	assert.NoError(t, os.WriteFile(from, []byte("a"), 0644))
+	assert.NoError(t, moveIfAbsent(from, to))
+	_, err := os.Stat(from)
+	assert.ErrorIs(t, err, os.ErrNotExist)
+	data, _ := os.ReadFile(to)
+	assert.Equal(t, "a", string(data))
+
+	// Don't clobber
+	assert.NoError(t, os.WriteFile(from, []byte("b"), 0644))
+	assert.NoError(t, moveIfAbsent(from, to))
+	data, _ = os.ReadFile(to)
+	assert.Equal(t, "a", string(data))
+}
+
+func TestConfigVersion(t *testing.T) {
+	store := MockFileStore(t)
+	store.relationsFile = filepath.Join(t.TempDir(), RELATIONS_FILE)
+	store.columnsFile = filepath.Join(t.TempDir(), COLUMNS_FILE)
+
+	relations := "# hand written\nversion: 1\nrules: []\n"
+	assert.NoError(t, os.WriteFile(store.relationsFile, []byte(relations), 0644))
+	_, err := store.ReadRelationRules()
+	assert.NoError(t, err)
+
+	assert.NoError(t, os.WriteFile(store.columnsFile, []byte("version: 99\ncolumnSets: []\n"), 0644))
+	_, err = store.ReadColumnSets()
+	assert.ErrorContains(t, err, "schema version 99 is newer")
+
+	// Configuration isn't rewritten
+	data, err := os.ReadFile(store.relationsFile)
+	assert.NoError(t, err)
+	assert.Equal(t, relations, string(data))
+}
@@ -2,21 +2,51 @@ package tabs
 
 import (
 	"fmt"
+	"slices"
 
 	"github.com/dchest/uniuri"
 )
 
+const (
+	// Bound on each of a tab's back and forward stacks
+	MAX_HISTORY = 50
+	// Bound on the recently closed tabs that can be reopened
+	MAX_CLOSED = 25
+)
+
 type Tab struct {
 	Id           string
 	K8sContext   string
 	K8sNamespace string
 	Path         string
 	Title        string
+	// Pinned tabs stay on the left and can't be closed until unpinned
+	Pinned  bool           `yaml:",omitempty"`
+	Back    []HistoryEntry `yaml:",omitempty"`
+	Forward []HistoryEntry `yaml:",omitempty"`
+}
+
+// HistoryEntry is a page a tab has visited.
+type HistoryEntry struct {
+	K8sContext   string
+	K8sNamespace string
+	Path         string
+	Title        string
 }
 
 type Tabs struct {
 	Current string
 	All     []*Tab
+	// Name of the workspace these tabs were saved as or switched from, if any
+	Workspace string `yaml:",omitempty"`
+	// Most recently closed last
+	Closed []ClosedTab `yaml:",omitempty"`
+}
+
+// ClosedTab remembers where the tab was so it can be reopened in the same place.
+type ClosedTab struct {
+	Tab   *Tab
+	Index int
 }
 
 func MakeInitialTabs() *Tabs {
@@ -25,10 +55,41 @@ func MakeInitialTabs() *Tabs {
 	return t
 }
 
+// Clone is a deep copy.
+func (t *Tabs) Clone() *Tabs {
+	c := &Tabs{
+		Current:   t.Current,
+		All:       make([]*Tab, len(t.All)),
+		Workspace: t.Workspace,
+	}
+	for i, tab := range t.All {
+		c.All[i] = tab.clone()
+	}
+	if t.Closed != nil {
+		c.Closed = make([]ClosedTab, len(t.Closed))
+		for i, closed := range t.Closed {
+			c.Closed[i] = ClosedTab{Tab: closed.Tab.clone(), Index: closed.Index}
+		}
+	}
+	return c
+}
+
+func (tab *Tab) clone() *Tab {
+	c := *tab
+	c.Back = slices.Clone(tab.Back)
+	c.Forward = slices.Clone(tab.Forward)
+	return &c
+}
+
 func (t *Tabs) Update(id string, k8sCtx string, k8sNs string, path string, title string) {
 	found, idx := t.findTab(id)
 	if found {
 		tab := t.All[idx]
+		if tab.isNavigation(k8sCtx, k8sNs, path) {
+			tab.Back = pushBounded(tab.Back, tab.entry())
+			tab.Forward = nil
+		}
+
 		if k8sCtx != "" {
 			tab.K8sContext = k8sCtx
 		}
@@ -46,16 +107,51 @@ func (t *Tabs) Update(id string, k8sCtx string, k8sNs string, path string, title
 
 }
 
+// GoBack returns the tab to the previous page in its history.
+func (t *Tabs) GoBack(id string) bool {
+	found, idx := t.findTab(id)
+	if !found || len(t.All[idx].Back) == 0 {
+		return false
+	}
+
+	tab := t.All[idx]
+	prev := tab.Back[len(tab.Back)-1]
+	tab.Back = tab.Back[:len(tab.Back)-1]
+	tab.Forward = pushBounded(tab.Forward, tab.entry())
+	tab.apply(prev)
+	return true
+}
+
+// GoForward undoes a GoBack.
+func (t *Tabs) GoForward(id string) bool {
+	found, idx := t.findTab(id)
+	if !found || len(t.All[idx].Forward) == 0 {
+		return false
+	}
+
+	tab := t.All[idx]
+	next := tab.Forward[len(tab.Forward)-1]
+	tab.Forward = tab.Forward[:len(tab.Forward)-1]
+	tab.Back = pushBounded(tab.Back, tab.entry())
+	tab.apply(next)
+	return true
+}
+
 func (t *Tabs) SelectTab(id string) {
 	t.Current = id
 }
 
 func (t *Tabs) CloseTab(id string) {
 	found, deleteIdx := t.findTab(id)
-	if found {
+	if found && !t.All[deleteIdx].Pinned {
 		deleteTab := t.All[deleteIdx]
 		t.All = append(t.All[:deleteIdx], t.All[deleteIdx+1:]...)
 
+		t.Closed = append(t.Closed, ClosedTab{Tab: deleteTab, Index: deleteIdx})
+		if len(t.Closed) > MAX_CLOSED {
+			t.Closed = t.Closed[len(t.Closed)-MAX_CLOSED:]
+		}
+
 		// Make sure there'a valid current tab
 		fmt.Printf("delete tab %s %s\n", deleteTab.Id, t.Current)
 		if deleteTab.Id == t.Current {
@@ -72,6 +168,98 @@ func (t *Tabs) CloseTab(id string) {
 	}
 }
 
+// ReopenClosedTab restores the most recently closed tab, like a browser's Cmd+Shift+T.
+func (t *Tabs) ReopenClosedTab() bool {
+	if len(t.Closed) == 0 {
+		return false
+	}
+	return t.RestoreClosedTab(t.Closed[len(t.Closed)-1].Tab.Id)
+}
+
+// RestoreClosedTab reopens a specific closed tab, with its history, where it used to be.
+func (t *Tabs) RestoreClosedTab(id string) bool {
+	for i, closed := range t.Closed {
+		if closed.Tab.Id != id {
+			continue
+		}
+
+		t.Closed = append(t.Closed[:i], t.Closed[i+1:]...)
+		t.insert(closed.Index, closed.Tab)
+		t.SelectTab(closed.Tab.Id)
+		return true
+	}
+	return false
+}
+
+// MoveTab moves a tab to idx, kept within the pinned or unpinned group it belongs to.
+func (t *Tabs) MoveTab(id string, idx int) bool {
+	found, currentIdx := t.findTab(id)
+	if !found {
+		return false
+	}
+
+	tab := t.All[currentIdx]
+	t.All = append(t.All[:currentIdx], t.All[currentIdx+1:]...)
+	t.insert(idx, tab)
+	return true
+}
+
+// DuplicateTab copies a tab, including its history, to a new tab next to it.
+func (t *Tabs) DuplicateTab(id string) bool {
+	found, idx := t.findTab(id)
+	if !found {
+		return false
+	}
+
+	dup := t.All[idx].clone()
+	dup.Id = uniuri.New()
+
+	t.insert(idx+1, dup)
+	t.SelectTab(dup.Id)
+	return true
+}
+
+// PinTab pins a tab to the end of the pinned group, or unpins it to the start of the unpinned one.
+func (t *Tabs) PinTab(id string, pinned bool) bool {
+	found, idx := t.findTab(id)
+	if !found {
+		return false
+	}
+
+	tab := t.All[idx]
+	if tab.Pinned == pinned {
+		return false
+	}
+
+	t.All = append(t.All[:idx], t.All[idx+1:]...)
+	boundary := t.pinnedCount()
+	tab.Pinned = pinned
+	t.insert(boundary, tab)
+	return true
+}
+
+// insert puts tab at idx, clamped so pinned tabs stay before unpinned ones.
+func (t *Tabs) insert(idx int, tab *Tab) {
+	pinned := t.pinnedCount()
+	if tab.Pinned {
+		idx = min(max(idx, 0), pinned)
+	} else {
+		idx = min(max(idx, pinned), len(t.All))
+	}
+
+	t.All = slices.Insert(t.All, idx, tab)
+}
+
+func (t *Tabs) pinnedCount() int {
+	n := 0
+	for _, tab := range t.All {
+		if tab.Pinned {
+			n++
+		}
+	}
+	return n
+}
+
 func (t *Tabs) PrevTab() {
 	found, currentIdx := t.findTab(t.Current)
 	if found {
@@ -117,6 +305,56 @@ func (t *Tabs) NewTab() {
 	t.SelectTab(newTab.Id)
 }
 
+// isNavigation is true when an update moves the tab to a different page, rather than only
+// changing the title of the current one.
+func (tab *Tab) isNavigation(k8sCtx string, k8sNs string, path string) bool {
+	if tab.Path == "" {
+		return false
+	}
+	return (k8sCtx != "" && k8sCtx != tab.K8sContext) ||
+		(k8sNs != "" && k8sNs != tab.K8sNamespace) ||
+		(path != "" && path != tab.Path)
+}
+
+func (tab *Tab) entry() HistoryEntry {
+	return HistoryEntry{
+		K8sContext:   tab.K8sContext,
+		K8sNamespace: tab.K8sNamespace,
+		Path:         tab.Path,
+		Title:        tab.Title,
+	}
+}
+
+func (tab *Tab) apply(e HistoryEntry) {
+	tab.K8sContext = e.K8sContext
+	tab.K8sNamespace = e.K8sNamespace
+	tab.Path = e.Path
+	tab.Title = e.Title
+}
+
+// pushBounded appends e, dropping the oldest entries beyond MAX_HISTORY.
+func pushBounded(stack []HistoryEntry, e HistoryEntry) []HistoryEntry {
+	stack = append(stack, e)
+	if len(stack) > MAX_HISTORY {
+		stack = stack[len(stack)-MAX_HISTORY:]
+	}
+	return stack
+}
+
+// OpenTab opens a new tab on a page, e.g. from a bookmark.
+func (t *Tabs) OpenTab(k8sCtx string, k8sNs string, path string, title string) {
+	newTab := Tab{
+		Id:           uniuri.New(),
+		K8sContext:   k8sCtx,
+		K8sNamespace: k8sNs,
+		Path:         path,
+		Title:        title,
+	}
+
+	t.All = append(t.All, &newTab)
+	t.SelectTab(newTab.Id)
+}
+
 func wrapMod(a, b int) int {
 	// behave like python's % where -1 wraps to the end
 	c := a % b
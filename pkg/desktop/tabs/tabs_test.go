@@ -1,6 +1,7 @@
 package tabs
 
 import (
+	"fmt"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -79,3 +80,174 @@ func threeTabs() Tabs {
 		Current: "two",
 	}
 }
+
+func TestHistory(t *testing.T) {
+	tabs := Tabs{}
+	tabs.NewTab()
+	id := tabs.Current
+
+	tabs.Update(id, "kind", "", "/kind", "contexts")
+	tabs.Update(id, "", "default", "/kind/default", "default")
+	// Title only changes aren't navigation
+	tabs.Update(id, "", "", "/kind/default", "default pods")
+	tabs.Update(id, "", "", "/kind/default/pod/web", "web")
+
+	tab := tabs.All[0]
+	assert.Len(t, tab.Back, 3)
+	assert.Empty(t, tab.Forward)
+
+	assert.True(t, tabs.GoBack(id))
+	assert.Equal(t, "/kind/default", tab.Path)
+	assert.Equal(t, "default pods", tab.Title)
+
+	assert.True(t, tabs.GoBack(id))
+	assert.Equal(t, "/kind", tab.Path)
+	assert.Equal(t, "", tab.K8sNamespace)
+	assert.Len(t, tab.Forward, 2)
+
+	assert.True(t, tabs.GoForward(id))
+	assert.Equal(t, "/kind/default", tab.Path)
+	assert.Equal(t, "default", tab.K8sNamespace)
+
+	// Navigating clears forward
+	tabs.Update(id, "", "", "/kind/default/svc/web", "web")
+	assert.Empty(t, tab.Forward)
+	assert.False(t, tabs.GoForward(id))
+	assert.Len(t, tab.Back, 3)
+}
+
+func TestHistoryBounded(t *testing.T) {
+	tabs := Tabs{}
+	tabs.NewTab()
+	id := tabs.Current
+
+	for i := 0; i < MAX_HISTORY+10; i++ {
+		tabs.Update(id, "", "", fmt.Sprintf("/%d", i), "")
+	}
+
+	tab := tabs.All[0]
+	assert.Len(t, tab.Back, MAX_HISTORY)
+	assert.Equal(t, fmt.Sprintf("/%d", MAX_HISTORY+8), tab.Back[MAX_HISTORY-1].Path)
+}
+
+func TestReopenClosedTab(t *testing.T) {
+	tabs := threeTabs()
+	tabs.All[1].Back = []HistoryEntry{{Path: "/before"}}
+
+	tabs.CloseTab("two")
+	tabs.CloseTab("one")
+	assert.Len(t, tabs.All, 1)
+	assert.Len(t, tabs.Closed, 2)
+
+	assert.True(t, tabs.ReopenClosedTab())
+	assert.Equal(t, "one", tabs.Current)
+	assert.Equal(t, []string{"one", "three"}, tabIds(tabs))
+
+	assert.True(t, tabs.ReopenClosedTab())
+	assert.Equal(t, "two", tabs.Current)
+	assert.Equal(t, []string{"one", "two", "three"}, tabIds(tabs))
+	assert.Equal(t, "/before", tabs.All[1].Back[0].Path)
+
+	assert.False(t, tabs.ReopenClosedTab())
+}
+
+func TestRestoreClosedTab(t *testing.T) {
+	tabs := threeTabs()
+	tabs.CloseTab("one")
+	tabs.CloseTab("two")
+	tabs.CloseTab("three")
+	assert.Len(t, tabs.All, 1)
+
+	assert.True(t, tabs.RestoreClosedTab("one"))
+	assert.Equal(t, "one", tabs.All[0].Id)
+	assert.Len(t, tabs.Closed, 2)
+	assert.False(t, tabs.RestoreClosedTab("one"))
+
+	for i := 0; i < MAX_CLOSED+5; i++ {
+		tabs.NewTab()
+		tabs.CloseTab(tabs.Current)
+	}
+	assert.Len(t, tabs.Closed, MAX_CLOSED)
+}
+
+func tabIds(tabs Tabs) []string {
+	ids := make([]string, len(tabs.All))
+	for i, tab := range tabs.All {
+		ids[i] = tab.Id
+	}
+	return ids
+}
+
+func TestMoveTab(t *testing.T) {
+	tabs := threeTabs()
+
+	assert.True(t, tabs.MoveTab("one", 2))
+	assert.Equal(t, []string{"two", "three", "one"}, tabIds(tabs))
+
+	assert.True(t, tabs.MoveTab("one", -5))
+	assert.Equal(t, []string{"one", "two", "three"}, tabIds(tabs))
+
+	assert.False(t, tabs.MoveTab("nope", 0))
+}
+
+func TestPinTab(t *testing.T) {
+	tabs := threeTabs()
+
+	assert.True(t, tabs.PinTab("three", true))
+	assert.Equal(t, []string{"three", "one", "two"}, tabIds(tabs))
+	assert.True(t, tabs.PinTab("two", true))
+	assert.Equal(t, []string{"three", "two", "one"}, tabIds(tabs))
+	assert.False(t, tabs.PinTab("two", true))
+
+	// Unpinned tabs can't move into the pinned group and vice versa
+	tabs.MoveTab("one", 0)
+	assert.Equal(t, []string{"three", "two", "one"}, tabIds(tabs))
+	tabs.MoveTab("three", 5)
+	assert.Equal(t, []string{"two", "three", "one"}, tabIds(tabs))
+
+	// Pinned tabs can't be closed
+	tabs.CloseTab("two")
+	assert.Len(t, tabs.All, 3)
+
+	assert.True(t, tabs.PinTab("three", false))
+	assert.Equal(t, []string{"two", "three", "one"}, tabIds(tabs))
+	assert.False(t, tabs.All[1].Pinned)
+	tabs.CloseTab("three")
+	assert.Equal(t, []string{"two", "one"}, tabIds(tabs))
+}
+
+func TestDuplicateTab(t *testing.T) {
+	tabs := threeTabs()
+	tabs.All[0].Path = "/kind"
+	tabs.All[0].Back = []HistoryEntry{{Path: "/"}}
+
+	assert.True(t, tabs.DuplicateTab("one"))
+	assert.Len(t, tabs.All, 4)
+
+	dup := tabs.All[1]
+	assert.NotEqual(t, "one", dup.Id)
+	assert.Equal(t, dup.Id, tabs.Current)
+	assert.Equal(t, "/kind", dup.Path)
+	assert.Equal(t, tabs.All[0].Back, dup.Back)
+
+	// History is copied, not shared
+	tabs.Update(dup.Id, "", "", "/kind/default", "")
+	assert.Len(t, tabs.All[0].Back, 1)
+	assert.Len(t, dup.Back, 2)
+}
+
+func TestClone(t *testing.T) {
+	tabs := threeTabs()
+	tabs.All[0].Back = []HistoryEntry{{Path: "/"}}
+	tabs.CloseTab("three")
+
+	c := tabs.Clone()
+	assert.Equal(t, &tabs, c)
+
+	c.All[0].Title = "changed"
+	c.All[0].Back[0].Path = "/changed"
+	c.Closed[0].Tab.Title = "changed"
+	assert.Equal(t, "", tabs.All[0].Title)
+	assert.Equal(t, "/", tabs.All[0].Back[0].Path)
+	assert.Equal(t, "", tabs.Closed[0].Tab.Title)
+}
@@ -0,0 +1,342 @@
+package kube
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	runtime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/dynamic"
+)
+
+// Field manager for server side apply
+const FIELD_MANAGER = "bosun"
+
+// Kinds that are regenerated by the cluster and never belong in a backup
+var backupSkipKinds = []string{"Event", "Endpoints", "EndpointSlice", "Lease", "ControllerRevision"}
+
+// metadata set by the server
+var serverMetadata = []string{
+	"managedFields",
+	"uid",
+	"resourceVersion",
+	"generation",
+	"creationTimestamp",
+	"deletionTimestamp",
+	"deletionGracePeriodSeconds",
+	"selfLink",
+	"namespace",
+}
+
+var serverAnnotations = []string{
+	"kubectl.kubernetes.io/last-applied-configuration",
+	"deployment.kubernetes.io/revision",
+}
+
+type BackupResult struct {
+	Dir string `json:"dir"`
+	// Manifests written
+	Files   int      `json:"files"`
+	Skipped []string `json:"skipped"`
+	Errors  []string `json:"errors"`
+}
+
+type ApplyResult struct {
+	File      string `json:"file"`
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	DryRun    bool   `json:"dryRun"`
+	// Another field manager owns fields the manifest sets. Nothing was applied.
+	Conflict bool   `json:"conflict"`
+	Error    string `json:"error"`
+}
+
+// BackupNamespace writes every object in the namespace, or only those of kinds, to dir as clean
+// manifests, one file per object. Objects owned by another object are skipped because their owner
+// recreates them.
+func (kc *KubeCluster) BackupNamespace(ctx context.Context, nsName string, kinds []string, dir string) (*BackupResult, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("unable to create dir %s: %w", dir, err)
+	}
+
+	result := &BackupResult{Dir: dir, Skipped: []string{}, Errors: []string{}}
+	for _, ar := range kc.backupResources(kinds) {
+		items, err := listAll(ctx, kc.dynamicClient.Resource(toGVR(ar)).Namespace(nsName))
+		if err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("unable to list %s: %s", ar.Name, err))
+			continue
+		}
+
+		for i := range items {
+			u := &items[i]
+			if reason := skipBackup(u); reason != "" {
+				result.Skipped = append(result.Skipped, fmt.Sprintf("%s/%s: %s", ar.Kind, u.GetName(), reason))
+				continue
+			}
+
+			if err := writeManifest(dir, ar, u); err != nil {
+				result.Errors = append(result.Errors, err.Error())
+				continue
+			}
+			result.Files++
+		}
+	}
+	return result, nil
+}
+
+// listAll follows Continue so a backup has every object, not only the first LIST_LIMIT.
+func listAll(ctx context.Context, ri dynamic.ResourceInterface) ([]unstructured.Unstructured, error) {
+	items := make([]unstructured.Unstructured, 0)
+	opts := metav1.ListOptions{Limit: LIST_LIMIT}
+	for {
+		uList, err := ri.List(ctx, opts)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, uList.Items...)
+		if uList.GetContinue() == "" {
+			return items, nil
+		}
+		opts.Continue = uList.GetContinue()
+	}
+}
+
+// backupResources are the namespaced kinds that can be listed and created.
+func (kc *KubeCluster) backupResources(kinds []string) []metav1.APIResource {
+	var candidates []metav1.APIResource
+	if len(kinds) == 0 {
+		candidates = kc.apiResources
+	} else {
+		for _, kind := range kinds {
+			candidates = append(candidates, resolveIdentifier(kc.apiResources, kind)...)
+		}
+	}
+
+	resources := make([]metav1.APIResource, 0)
+	for _, ar := range candidates {
+		if !ar.Namespaced || slices.Contains(backupSkipKinds, ar.Kind) {
+			continue
+		}
+		if !slices.Contains(ar.Verbs, "list") || !slices.Contains(ar.Verbs, "create") {
+			continue
+		}
+		if slices.ContainsFunc(resources, func(r metav1.APIResource) bool { return toGVR(r) == toGVR(ar) }) {
+			continue
+		}
+		resources = append(resources, ar)
+	}
+	return resources
+}
+
+// skipBackup explains why u doesn't belong in a backup, or is empty.
+func skipBackup(u *unstructured.Unstructured) string {
+	if len(u.GetOwnerReferences()) > 0 {
+		return "owned by " + u.GetOwnerReferences()[0].Kind
+	}
+	switch {
+	case u.GetKind() == "ServiceAccount" && u.GetName() == "default":
+		return "created with the namespace"
+	case u.GetKind() == "ConfigMap" && u.GetName() == "kube-root-ca.crt":
+		return "created with the namespace"
+	case u.GetKind() == "Secret":
+		if t, _, _ := unstructured.NestedString(u.Object, "type"); t == "kubernetes.io/service-account-token" {
+			return "service account token"
+		}
+	}
+	return ""
+}
+
+// CleanObject removes status and the fields the server sets, so the object can be applied to
+// another namespace or cluster.
+func CleanObject(u *unstructured.Unstructured) {
+	delete(u.Object, "status")
+
+	if md, ok := u.Object["metadata"].(map[string]interface{}); ok {
+		for _, f := range serverMetadata {
+			delete(md, f)
+		}
+		if annotations, ok := md["annotations"].(map[string]interface{}); ok {
+			for _, a := range serverAnnotations {
+				delete(annotations, a)
+			}
+			if len(annotations) == 0 {
+				delete(md, "annotations")
+			}
+		}
+	}
+
+	// Addresses the cluster assigned
+	if u.GetKind() == "Service" {
+		if ip, _, _ := unstructured.NestedString(u.Object, "spec", "clusterIP"); ip != "None" {
+			unstructured.RemoveNestedField(u.Object, "spec", "clusterIP")
+			unstructured.RemoveNestedField(u.Object, "spec", "clusterIPs")
+		}
+		unstructured.RemoveNestedField(u.Object, "spec", "healthCheckNodePort")
+	}
+	if u.GetKind() == "PersistentVolumeClaim" {
+		unstructured.RemoveNestedField(u.Object, "spec", "volumeName")
+	}
+}
+
+func writeManifest(dir string, ar metav1.APIResource, u *unstructured.Unstructured) error {
+	CleanObject(u)
+	doc, err := renderYaml(u)
+	if err != nil {
+		return fmt.Errorf("unable to render %s/%s: %w", ar.Kind, u.GetName(), err)
+	}
+
+	resourceDir := ar.Name
+	if ar.Group != "" {
+		resourceDir += "." + ar.Group
+	}
+	if err := os.MkdirAll(filepath.Join(dir, resourceDir), 0755); err != nil {
+		return fmt.Errorf("unable to create dir %s: %w", resourceDir, err)
+	}
+
+	file := filepath.Join(dir, resourceDir, manifestFilename(u.GetName()))
+	if err := os.WriteFile(file, []byte(doc), 0644); err != nil {
+		return fmt.Errorf("unable to write %s: %w", file, err)
+	}
+	return nil
+}
+
+// manifestFilename replaces characters that names may contain but filenames can't, e.g. the colon
+// in system:controller.
+func manifestFilename(name string) string {
+	return strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`/\:*?"<>|`, r) {
+			return '_'
+		}
+		return r
+	}, name) + ".yaml"
+}
+
+// manifest is one object read from a file.
+type manifest struct {
+	file string
+	obj  *unstructured.Unstructured
+}
+
+// readManifests reads every YAML document in the files under dir.
+func readManifests(dir string) ([]manifest, error) {
+	manifests := make([]manifest, 0)
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		ext := filepath.Ext(path)
+		if d.IsDir() || (ext != ".yaml" && ext != ".yml") {
+			return nil
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("unable to read %s: %w", path, err)
+		}
+
+		decoder := utilyaml.NewYAMLOrJSONDecoder(bytes.NewReader(data), 4096)
+		for {
+			raw := runtime.RawExtension{}
+			err := decoder.Decode(&raw)
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				return fmt.Errorf("unable to parse %s: %w", path, err)
+			}
+			if len(raw.Raw) == 0 {
+				continue
+			}
+
+			u := &unstructured.Unstructured{}
+			if err := u.UnmarshalJSON(raw.Raw); err != nil {
+				return fmt.Errorf("unable to parse %s: %w", path, err)
+			}
+			manifests = append(manifests, manifest{file: path, obj: u})
+		}
+		return nil
+	})
+	return manifests, err
+}
+
+// ApplyManifests server side applies the manifests in dir to the namespace. Every manifest is dry
+// run first. Unless dryRun, the manifests are then applied, but only when all the dry runs
+// succeeded, so a rejected manifest doesn't leave the namespace half restored.
+func (kc *KubeCluster) ApplyManifests(ctx context.Context, dir string, nsName string, dryRun bool) ([]ApplyResult, error) {
+	manifests, err := readManifests(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	results := kc.applyAll(ctx, dir, manifests, nsName, true)
+	if dryRun || slices.ContainsFunc(results, func(r ApplyResult) bool { return r.Error != "" }) {
+		return results, nil
+	}
+	return kc.applyAll(ctx, dir, manifests, nsName, false), nil
+}
+
+func (kc *KubeCluster) applyAll(ctx context.Context, dir string, manifests []manifest, nsName string, dryRun bool) []ApplyResult {
+	results := make([]ApplyResult, 0, len(manifests))
+	for _, m := range manifests {
+		rel, err := filepath.Rel(dir, m.file)
+		if err != nil {
+			rel = m.file
+		}
+		result := ApplyResult{File: rel, Kind: m.obj.GetKind(), Name: m.obj.GetName(), DryRun: dryRun}
+		if err := kc.applyManifest(ctx, m.obj, nsName, dryRun); err != nil {
+			result.Conflict = apierrors.IsConflict(err)
+			result.Error = err.Error()
+		}
+		result.Namespace = m.obj.GetNamespace()
+		results = append(results, result)
+	}
+	return results
+}
+
+func (kc *KubeCluster) applyManifest(ctx context.Context, u *unstructured.Unstructured, nsName string, dryRun bool) error {
+	gvk := u.GroupVersionKind()
+	matches := findAPIResources(kc.apiResources, gvk.Group, gvk.Kind)
+	if len(matches) == 0 {
+		return fmt.Errorf("unable to find an api resource: %s", gvk.Kind)
+	}
+	ar := matches[0]
+	// Apply the manifest's version. The server converts it.
+	ar.Version = gvk.Version
+
+	CleanObject(u)
+	if ar.Namespaced {
+		u.SetNamespace(nsName)
+	}
+
+	data, err := u.MarshalJSON()
+	if err != nil {
+		return fmt.Errorf("unable to marshal %s/%s: %w", gvk.Kind, u.GetName(), err)
+	}
+
+	// Without Force, fields owned by another manager are a conflict rather than taken over
+	opts := metav1.PatchOptions{FieldManager: FIELD_MANAGER}
+	if dryRun {
+		opts.DryRun = []string{metav1.DryRunAll}
+	}
+
+	ri := kc.dynamicClient.Resource(toGVR(ar))
+	if ar.Namespaced {
+		_, err = ri.Namespace(nsName).Patch(ctx, u.GetName(), types.ApplyPatchType, data, opts)
+	} else {
+		_, err = ri.Patch(ctx, u.GetName(), types.ApplyPatchType, data, opts)
+	}
+	return err
+}
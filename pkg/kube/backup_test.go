@@ -0,0 +1,225 @@
+package kube
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	runtime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testBackupCluster(objs ...runtime.Object) (*KubeCluster, *dynamicfake.FakeDynamicClient) {
+	verbs := metav1.Verbs{"get", "list", "create", "patch"}
+	client := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		{Version: "v1", Resource: "configmaps"}:                 "ConfigMapList",
+		{Version: "v1", Resource: "services"}:                   "ServiceList",
+		{Version: "v1", Resource: "events"}:                     "EventList",
+		{Group: "apps", Version: "v1", Resource: "replicasets"}: "ReplicaSetList",
+	}, objs...)
+
+	return &KubeCluster{
+		dynamicClient: client,
+		apiResources: []metav1.APIResource{
+			{Name: "configmaps", Kind: "ConfigMap", Version: "v1", Namespaced: true, Verbs: verbs, ShortNames: []string{"cm"}},
+			{Name: "services", Kind: "Service", Version: "v1", Namespaced: true, Verbs: verbs, ShortNames: []string{"svc"}},
+			{Name: "events", Kind: "Event", Version: "v1", Namespaced: true, Verbs: verbs},
+			{Name: "replicasets", Kind: "ReplicaSet", Group: "apps", Version: "v1", Namespaced: true, Verbs: verbs},
+			{Name: "namespaces", Kind: "Namespace", Version: "v1", Verbs: verbs},
+		},
+	}, client
+}
+
+func testObject(apiVersion string, kind string, name string, fields map[string]interface{}) *unstructured.Unstructured {
+	u := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": apiVersion,
+		"kind":       kind,
+		"metadata": map[string]interface{}{
+			"name":              name,
+			"namespace":         "shop",
+			"uid":               "a1b2",
+			"resourceVersion":   "42",
+			"creationTimestamp": "2024-01-01T00:00:00Z",
+			"managedFields":     []interface{}{map[string]interface{}{"manager": "kubectl"}},
+			"annotations": map[string]interface{}{
+				"kubectl.kubernetes.io/last-applied-configuration": "{}",
+			},
+		},
+		"status": map[string]interface{}{"ready": true},
+	}}
+	for k, v := range fields {
+		u.Object[k] = v
+	}
+	return u
+}
+
+func TestCleanObject(t *testing.T) {
+	u := testObject("v1", "Service", "web", map[string]interface{}{
+		"spec": map[string]interface{}{
+			"clusterIP":  "10.0.0.1",
+			"clusterIPs": []interface{}{"10.0.0.1"},
+			"ports":      []interface{}{map[string]interface{}{"port": int64(80)}},
+		},
+	})
+	CleanObject(u)
+
+	assert.Equal(t, map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Service",
+		"metadata":   map[string]interface{}{"name": "web"},
+		"spec": map[string]interface{}{
+			"ports": []interface{}{map[string]interface{}{"port": int64(80)}},
+		},
+	}, u.Object)
+
+	headless := testObject("v1", "Service", "db", map[string]interface{}{
+		"spec": map[string]interface{}{"clusterIP": "None"},
+	})
+	CleanObject(headless)
+	ip, _, _ := unstructured.NestedString(headless.Object, "spec", "clusterIP")
+	assert.Equal(t, "None", ip)
+}
+
+func TestBackupAndApply(t *testing.T) {
+	owned := testObject("apps/v1", "ReplicaSet", "web-abc", nil)
+	owned.SetOwnerReferences([]metav1.OwnerReference{{Kind: "Deployment", Name: "web"}})
+
+	kc, client := testBackupCluster(
+		testObject("v1", "ConfigMap", "settings", map[string]interface{}{"data": map[string]interface{}{"a": "1"}}),
+		testObject("v1", "ConfigMap", "kube-root-ca.crt", nil),
+		testObject("v1", "Service", "web", nil),
+		testObject("v1", "Event", "web.123", nil),
+		owned,
+	)
+	dir := t.TempDir()
+
+	result, err := kc.BackupNamespace(context.Background(), "shop", nil, dir)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, result.Files)
+	assert.Len(t, result.Skipped, 2)
+	assert.Empty(t, result.Errors)
+
+	data, err := os.ReadFile(filepath.Join(dir, "configmaps", "settings.yaml"))
+	assert.NoError(t, err)
+	assert.Equal(t, "apiVersion: v1\ndata:\n    a: \"1\"\nkind: ConfigMap\nmetadata:\n    name: settings\n", string(data))
+	assert.NoFileExists(t, filepath.Join(dir, "events", "web.123.yaml"))
+
+	// Only the requested kinds
+	onlyDir := t.TempDir()
+	result, err = kc.BackupNamespace(context.Background(), "shop", []string{"svc"}, onlyDir)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, result.Files)
+	assert.FileExists(t, filepath.Join(onlyDir, "services", "web.yaml"))
+
+	var patched []k8stesting.PatchAction
+	client.PrependReactor("patch", "*", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		patched = append(patched, action.(k8stesting.PatchAction))
+		return true, &unstructured.Unstructured{}, nil
+	})
+
+	results, err := kc.ApplyManifests(context.Background(), dir, "shop-restore", true)
+	assert.NoError(t, err)
+	assert.Len(t, results, 2)
+	for _, r := range results {
+		assert.Empty(t, r.Error)
+		assert.True(t, r.DryRun)
+		assert.Equal(t, "shop-restore", r.Namespace)
+	}
+	assert.Len(t, patched, 2)
+	assert.Equal(t, "shop-restore", patched[0].GetNamespace())
+	assert.Contains(t, string(patched[0].GetPatch()), `"namespace":"shop-restore"`)
+
+	// Dry run again, then apply
+	patched = nil
+	results, err = kc.ApplyManifests(context.Background(), dir, "shop-restore", false)
+	assert.NoError(t, err)
+	assert.Len(t, results, 2)
+	assert.False(t, results[0].DryRun)
+	// The fake client drops the patch options, so only the number of patches shows the dry run
+	assert.Len(t, patched, 4)
+}
+
+func TestApplyConflict(t *testing.T) {
+	kc, client := testBackupCluster()
+	dir := t.TempDir()
+	assert.NoError(t, writeManifest(dir, kc.apiResources[0], testObject("v1", "ConfigMap", "settings", nil)))
+	assert.NoError(t, writeManifest(dir, kc.apiResources[1], testObject("v1", "Service", "web", nil)))
+
+	var patched []k8stesting.PatchAction
+	client.PrependReactor("patch", "*", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		patch := action.(k8stesting.PatchAction)
+		patched = append(patched, patch)
+		if patch.GetName() == "web" {
+			return true, nil, apierrors.NewConflict(schema.GroupResource{Resource: "services"}, "web", errors.New(`conflict with "kubectl"`))
+		}
+		return true, &unstructured.Unstructured{}, nil
+	})
+
+	// Nothing is applied after a failed dry run
+	results, err := kc.ApplyManifests(context.Background(), dir, "shop", false)
+	assert.NoError(t, err)
+	assert.Len(t, results, 2)
+	assert.Len(t, patched, 2)
+	for _, r := range results {
+		assert.True(t, r.DryRun)
+		assert.Equal(t, r.Name == "web", r.Conflict)
+	}
+}
+
+func TestBackupPages(t *testing.T) {
+	kc, client := testBackupCluster()
+	first := &unstructured.UnstructuredList{Items: []unstructured.Unstructured{*testObject("v1", "ConfigMap", "a", nil)}}
+	first.SetContinue("2")
+	last := &unstructured.UnstructuredList{Items: []unstructured.Unstructured{*testObject("v1", "ConfigMap", "b", nil)}}
+
+	// The fake client drops Continue, so pages are served in order
+	pages := []*unstructured.UnstructuredList{first, last}
+	client.PrependReactor("list", "configmaps", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		page := pages[0]
+		pages = pages[1:]
+		return true, page, nil
+	})
+
+	result, err := kc.BackupNamespace(context.Background(), "shop", []string{"cm"}, t.TempDir())
+	assert.NoError(t, err)
+	assert.Equal(t, 2, result.Files)
+	assert.Empty(t, pages)
+}
+
+func TestReadManifests(t *testing.T) {
+	dir := t.TempDir()
+	err := os.WriteFile(filepath.Join(dir, "all.yml"), []byte(`
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: a
+data:
+  replicas: "3"
+---
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  replicas: 3
+`), 0644)
+	assert.NoError(t, err)
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("not a manifest"), 0644))
+
+	manifests, err := readManifests(dir)
+	assert.NoError(t, err)
+	assert.Len(t, manifests, 2)
+	assert.Equal(t, "Deployment", manifests[1].obj.GetKind())
+	replicas, _, _ := unstructured.NestedInt64(manifests[1].obj.Object, "spec", "replicas")
+	assert.Equal(t, int64(3), replicas)
+}
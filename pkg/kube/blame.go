@@ -0,0 +1,177 @@
+package kube
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// FieldOwner is a manager that set a field, from the object's managedFields.
+type FieldOwner struct {
+	Manager     string `json:"manager"`
+	Operation   string `json:"operation"`
+	Subresource string `json:"subresource"`
+	// Last time the manager changed any of its fields. Empty when the server didn't record it.
+	Time *time.Time `json:"time"`
+}
+
+// FieldOwners decodes the managedFields of u into the owners of each field, most recent first. Paths
+// are like the references' properties, e.g. .spec.template.spec.containers[0].image. Items of
+// lists keyed by name or value are resolved to their index in u, and skipped when u no longer has
+// them.
+func FieldOwners(u *unstructured.Unstructured) (map[string][]FieldOwner, error) {
+	owners := make(map[string][]FieldOwner)
+	for _, mf := range u.GetManagedFields() {
+		if mf.FieldsV1 == nil || mf.FieldsType != "FieldsV1" {
+			continue
+		}
+
+		var fields map[string]interface{}
+		if err := json.Unmarshal(mf.FieldsV1.Raw, &fields); err != nil {
+			return owners, fmt.Errorf("unable to decode fields of %s: %w", mf.Manager, err)
+		}
+
+		owner := FieldOwner{
+			Manager:     mf.Manager,
+			Operation:   string(mf.Operation),
+			Subresource: mf.Subresource,
+		}
+		if mf.Time != nil {
+			t := mf.Time.Time
+			owner.Time = &t
+		}
+		walkFieldSet(fields, u.Object, "", func(path string) {
+			owners[path] = append(owners[path], owner)
+		})
+	}
+
+	for _, fos := range owners {
+		sort.SliceStable(fos, func(i, j int) bool {
+			if fos[i].Time == nil || fos[j].Time == nil {
+				return fos[i].Time != nil
+			}
+			return fos[i].Time.After(*fos[j].Time)
+		})
+	}
+	return owners, nil
+}
+
+// walkFieldSet calls owned with the path of every field in the set. A set is a tree of
+// "f:<field>", "k:<keys>", "v:<value>" and "i:<index>" entries. An empty entry is a field. The "."
+// entry means the parent itself is owned too.
+func walkFieldSet(set map[string]interface{}, value interface{}, path string, owned func(string)) {
+	for key, child := range set {
+		if key == "." {
+			if path != "" {
+				owned(path)
+			}
+			continue
+		}
+
+		childPath, childValue, ok := fieldSetChild(key, value, path)
+		if !ok {
+			continue
+		}
+
+		childSet, _ := child.(map[string]interface{})
+		if len(childSet) == 0 {
+			owned(childPath)
+			continue
+		}
+		walkFieldSet(childSet, childValue, childPath, owned)
+	}
+}
+
+// fieldSetChild resolves one entry of a field set against value, the part of the object at path.
+func fieldSetChild(key string, value interface{}, path string) (string, interface{}, bool) {
+	prefix, rest, found := strings.Cut(key, ":")
+	if !found {
+		return "", nil, false
+	}
+
+	if prefix == "f" {
+		m, _ := value.(map[string]interface{})
+		v, ok := m[rest]
+		return path + "." + rest, v, ok
+	}
+
+	list, ok := value.([]interface{})
+	if !ok {
+		return "", nil, false
+	}
+	idx := -1
+	switch prefix {
+	case "i":
+		if i, err := strconv.Atoi(rest); err == nil && i >= 0 && i < len(list) {
+			idx = i
+		}
+
+	case "k":
+		var keys map[string]interface{}
+		if err := json.Unmarshal([]byte(rest), &keys); err != nil {
+			return "", nil, false
+		}
+		idx = indexOfKeys(list, keys)
+
+	case "v":
+		var v interface{}
+		if err := json.Unmarshal([]byte(rest), &v); err != nil {
+			return "", nil, false
+		}
+		for i, item := range list {
+			if jsonEqual(item, v) {
+				idx = i
+				break
+			}
+		}
+	}
+
+	if idx == -1 {
+		return "", nil, false
+	}
+	return fmt.Sprintf("%s[%d]", path, idx), list[idx], true
+}
+
+func indexOfKeys(list []interface{}, keys map[string]interface{}) int {
+	for i, item := range list {
+		m, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		matches := true
+		for k, v := range keys {
+			if !jsonEqual(m[k], v) {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			return i
+		}
+	}
+	return -1
+}
+
+// jsonEqual compares an object's value with one decoded from JSON, where every number is a
+// float64.
+func jsonEqual(a interface{}, b interface{}) bool {
+	aj, err := json.Marshal(a)
+	if err != nil {
+		return false
+	}
+	bj, err := json.Marshal(b)
+	if err != nil {
+		return false
+	}
+	var an, bn interface{}
+	if json.Unmarshal(aj, &an) != nil || json.Unmarshal(bj, &bn) != nil {
+		return false
+	}
+	return reflect.DeepEqual(an, bn)
+}
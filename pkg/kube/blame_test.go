@@ -0,0 +1,92 @@
+package kube
+
+import (
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFieldOwners(t *testing.T) {
+	u := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata":   map[string]interface{}{"name": "web", "namespace": "shop", "finalizers": []interface{}{"a", "b"}},
+		"spec": map[string]interface{}{
+			"replicas": int64(1),
+			"template": map[string]interface{}{
+				"spec": map[string]interface{}{
+					"containers": []interface{}{
+						map[string]interface{}{"name": "sidecar", "image": "envoy"},
+						map[string]interface{}{
+							"name":  "web",
+							"image": "nginx",
+							"ports": []interface{}{map[string]interface{}{"containerPort": int64(80), "protocol": "TCP"}},
+						},
+					},
+				},
+			},
+		},
+		"status": map[string]interface{}{"replicas": int64(1)},
+	}}
+
+	applied := metav1.NewTime(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	scaled := metav1.NewTime(time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC))
+	u.SetManagedFields([]metav1.ManagedFieldsEntry{
+		{
+			Manager: "argocd", Operation: metav1.ManagedFieldsOperationApply, Time: &applied, FieldsType: "FieldsV1",
+			FieldsV1: &metav1.FieldsV1{Raw: []byte(`{
+				"f:metadata": {"f:finalizers": {"v:\"b\"": {}}},
+				"f:spec": {
+					"f:replicas": {},
+					"f:template": {"f:spec": {"f:containers": {
+						"k:{\"name\":\"web\"}": {
+							".": {},
+							"f:image": {},
+							"f:ports": {"k:{\"containerPort\":80,\"protocol\":\"TCP\"}": {"f:containerPort": {}}}
+						},
+						"k:{\"name\":\"gone\"}": {"f:image": {}}
+					}}}
+				}
+			}`)},
+		},
+		{
+			Manager: "kubectl", Operation: metav1.ManagedFieldsOperationUpdate, Time: &scaled, FieldsType: "FieldsV1",
+			FieldsV1: &metav1.FieldsV1{Raw: []byte(`{"f:spec": {"f:replicas": {}}}`)},
+		},
+		{
+			Manager: "kube-controller-manager", Operation: metav1.ManagedFieldsOperationUpdate, Subresource: "status", FieldsType: "FieldsV1",
+			FieldsV1: &metav1.FieldsV1{Raw: []byte(`{"f:status": {"f:replicas": {}}}`)},
+		},
+	})
+
+	owners, err := FieldOwners(u)
+	assert.NoError(t, err)
+
+	managers := func(path string) []string {
+		names := []string{}
+		for _, o := range owners[path] {
+			names = append(names, o.Manager)
+		}
+		return names
+	}
+	assert.Equal(t, []string{"kubectl", "argocd"}, managers(".spec.replicas"))
+	assert.Equal(t, "Update", owners[".spec.replicas"][0].Operation)
+	assert.True(t, scaled.Time.Equal(*owners[".spec.replicas"][0].Time))
+
+	assert.Equal(t, []string{"argocd"}, managers(".spec.template.spec.containers[1]"))
+	assert.Equal(t, []string{"argocd"}, managers(".spec.template.spec.containers[1].image"))
+	assert.Equal(t, []string{"argocd"}, managers(".spec.template.spec.containers[1].ports[0].containerPort"))
+	assert.Equal(t, []string{"argocd"}, managers(".metadata.finalizers[1]"))
+	assert.Empty(t, managers(".spec.template.spec.containers[0].image"))
+
+	status := owners[".status.replicas"]
+	if assert.Len(t, status, 1) {
+		assert.Equal(t, "status", status[0].Subresource)
+		assert.Nil(t, status[0].Time)
+	}
+	assert.Len(t, owners, 6)
+}
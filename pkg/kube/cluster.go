@@ -7,6 +7,7 @@ import (
 	"sort"
 	"strings"
 
+	"bosun/pkg/kube/relations"
 	blog "bosun/pkg/logging"
 
 	"github.com/samber/lo"
@@ -29,9 +30,15 @@ type KubeCluster struct {
 	apiResources     []metav1.APIResource
 	scheme           *runtime.Scheme // Could be global since it's go types?
 	dynamicClient    dynamic.Interface
+	relationRules    []relations.Rule
+	columnSets       []ColumnSet
+	// Names for completions
+	names nameCache
+	// Schemas for Explain
+	openAPI openAPICache
 }
 
-func NewKubeCluster(kubeCtxName string) (*KubeCluster, error) {
+func NewKubeCluster(kubeCtxName string, relationRules []relations.Rule, columnSets []ColumnSet) (*KubeCluster, error) {
 
 	clientConfig := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
 		clientcmd.NewDefaultClientConfigLoadingRules(),
@@ -64,6 +71,8 @@ func NewKubeCluster(kubeCtxName string) (*KubeCluster, error) {
 		apiResources:     apiResource,
 		scheme:           scheme,
 		dynamicClient:    dynamicClient,
+		relationRules:    relationRules,
+		columnSets:       columnSets,
 	}, nil
 }
 
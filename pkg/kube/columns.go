@@ -0,0 +1,137 @@
+package kube
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"bosun/pkg/util"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/util/jsonpath"
+)
+
+// Shown for a column whose path finds nothing, like kubectl custom-columns
+const COLUMN_NONE = "<none>"
+
+// ColumnFile is the on disk format for user defined table columns.
+type ColumnFile struct {
+	Version    int         `yaml:"version"`
+	ColumnSets []ColumnSet `yaml:"columnSets"`
+}
+
+// ColumnSet replaces the columns in tables of Group, Kind, or with Append adds to them.
+type ColumnSet struct {
+	Group   string   `yaml:"group"`
+	Kind    string   `yaml:"kind"`
+	Append  bool     `yaml:"append,omitempty"`
+	Columns []Column `yaml:"columns"`
+}
+
+type Column struct {
+	Name string `yaml:"name"`
+	// JSONPath evaluated on each object, e.g. {.spec.containers[*].image}
+	JSONPath string `yaml:"jsonPath"`
+}
+
+func (cs ColumnSet) GroupKind() schema.GroupKind {
+	return schema.GroupKind{Group: cs.Group, Kind: cs.Kind}
+}
+
+func (cs ColumnSet) Validate() error {
+	if cs.Kind == "" {
+		return fmt.Errorf("column set is missing kind")
+	}
+	if len(cs.Columns) == 0 {
+		return fmt.Errorf("column set for %s has no columns", cs.GroupKind())
+	}
+	for _, c := range cs.Columns {
+		if c.Name == "" {
+			return fmt.Errorf("column set for %s has a column without a name", cs.GroupKind())
+		}
+		if _, err := util.ParseJSONPath(c.JSONPath); err != nil {
+			return fmt.Errorf("column %s for %s has invalid jsonPath: %w", c.Name, cs.GroupKind(), err)
+		}
+	}
+	return nil
+}
+
+func findColumnSet(sets []ColumnSet, ar metav1.APIResource) (ColumnSet, bool) {
+	for _, cs := range sets {
+		if cs.Group == ar.Group && strings.EqualFold(cs.Kind, ar.Kind) {
+			return cs, true
+		}
+	}
+	return ColumnSet{}, false
+}
+
+// applyColumnSet adds or replaces the columns of table with values from uList. Rows and items
+// must correspond one to one.
+func applyColumnSet(table *metav1.Table, cs ColumnSet, uList *unstructured.UnstructuredList) error {
+	if len(table.Rows) != len(uList.Items) {
+		return fmt.Errorf("table has %d rows for %d objects", len(table.Rows), len(uList.Items))
+	}
+
+	paths := make([]*jsonpath.JSONPath, len(cs.Columns))
+	definitions := make([]metav1.TableColumnDefinition, len(cs.Columns))
+	for i, c := range cs.Columns {
+		jp, err := util.ParseJSONPath(c.JSONPath)
+		if err != nil {
+			return fmt.Errorf("column %s: %w", c.Name, err)
+		}
+		paths[i] = jp
+		definitions[i] = metav1.TableColumnDefinition{Name: c.Name, Type: "string", Description: c.JSONPath}
+	}
+
+	if cs.Append {
+		table.ColumnDefinitions = append(table.ColumnDefinitions, definitions...)
+	} else {
+		table.ColumnDefinitions = definitions
+	}
+
+	for i := range table.Rows {
+		cells := make([]interface{}, len(paths))
+		for j, jp := range paths {
+			cells[j] = columnValue(jp, uList.Items[i].Object)
+		}
+		if cs.Append {
+			table.Rows[i].Cells = append(table.Rows[i].Cells, cells...)
+		} else {
+			table.Rows[i].Cells = cells
+		}
+	}
+	return nil
+}
+
+// columnValue joins multiple results with commas, the way kubectl custom-columns does.
+func columnValue(jp *jsonpath.JSONPath, obj map[string]interface{}) string {
+	results, err := jp.FindResults(obj)
+	if err != nil {
+		return COLUMN_NONE
+	}
+
+	values := make([]string, 0)
+	for _, rs := range results {
+		for _, r := range rs {
+			switch v := r.Interface().(type) {
+			case nil:
+			case string, bool, int64, float64:
+				values = append(values, fmt.Sprint(v))
+			default:
+				bs, err := json.Marshal(v)
+				if err != nil {
+					values = append(values, fmt.Sprint(v))
+				} else {
+					values = append(values, string(bs))
+				}
+			}
+		}
+	}
+
+	if len(values) == 0 {
+		return COLUMN_NONE
+	}
+	return strings.Join(values, ",")
+}
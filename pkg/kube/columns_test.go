@@ -0,0 +1,93 @@
+package kube
+
+import (
+	"testing"
+
+	"bosun/pkg/util"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testPodList() *unstructured.UnstructuredList {
+	return &unstructured.UnstructuredList{
+		Items: []unstructured.Unstructured{
+			{Object: map[string]interface{}{
+				"metadata": map[string]interface{}{
+					"name":        "web-0",
+					"annotations": map[string]interface{}{"prometheus.io/scrape": "true"},
+				},
+				"spec": map[string]interface{}{
+					"containers": []interface{}{
+						map[string]interface{}{"name": "web", "image": "web:1.2.3"},
+						map[string]interface{}{"name": "proxy", "image": "envoy:1.29"},
+					},
+				},
+			}},
+			{Object: map[string]interface{}{
+				"metadata": map[string]interface{}{"name": "web-1"},
+				"spec": map[string]interface{}{
+					"containers": []interface{}{
+						map[string]interface{}{"name": "web", "image": "web:1.2.4"},
+					},
+				},
+			}},
+		},
+	}
+}
+
+func TestApplyColumnSet(t *testing.T) {
+	cs := ColumnSet{
+		Kind:   "Pod",
+		Append: true,
+		Columns: []Column{
+			{Name: "Images", JSONPath: ".spec.containers[*].image"},
+			{Name: "Scrape", JSONPath: `{.metadata.annotations['prometheus\.io/scrape']}`},
+		},
+	}
+	assert.NoError(t, cs.Validate())
+
+	uList := testPodList()
+	table, err := printUnstructured(uList)
+	assert.NoError(t, err)
+
+	assert.NoError(t, applyColumnSet(table, cs, uList))
+	assert.Equal(t, []string{"Name", "Age", "Images", "Scrape"}, columnNames(table))
+	assert.Equal(t, "web-0", table.Rows[0].Cells[0])
+	assert.Equal(t, "web:1.2.3,envoy:1.29", table.Rows[0].Cells[2])
+	assert.Equal(t, "true", table.Rows[0].Cells[3])
+	assert.Equal(t, COLUMN_NONE, table.Rows[1].Cells[3])
+
+	cs.Append = false
+	table, _ = printUnstructured(uList)
+	assert.NoError(t, applyColumnSet(table, cs, uList))
+	assert.Equal(t, []string{"Images", "Scrape"}, columnNames(table))
+	assert.Len(t, table.Rows[1].Cells, 2)
+}
+
+func TestValidColumnSets(t *testing.T) {
+	valid, errs := util.Valid([]ColumnSet{
+		{Kind: "Pod", Columns: []Column{{Name: "Node", JSONPath: ".spec.nodeName"}}},
+		{Kind: "Pod"},
+		{Columns: []Column{{Name: "Node", JSONPath: ".spec.nodeName"}}},
+		{Kind: "Pod", Columns: []Column{{Name: "Bad", JSONPath: "{.spec["}}},
+	})
+	assert.Len(t, valid, 1)
+	assert.Len(t, errs, 3)
+
+	cs, found := findColumnSet(valid, metav1.APIResource{Kind: "Pod"})
+	assert.True(t, found)
+	assert.Equal(t, "Node", cs.Columns[0].Name)
+	_, found = findColumnSet(valid, metav1.APIResource{Group: "apps", Kind: "Deployment"})
+	assert.False(t, found)
+}
+
+func columnNames(table *metav1.Table) []string {
+	names := make([]string, len(table.ColumnDefinitions))
+	for i, cd := range table.ColumnDefinitions {
+		names[i] = cd.Name
+	}
+	return names
+}
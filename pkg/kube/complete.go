@@ -0,0 +1,227 @@
+package kube
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/dynamic"
+)
+
+// How long object and namespace names are reused for completions
+const COMPLETION_CACHE_TTL = 30 * time.Second
+
+const (
+	CompletionCommand   = "command"
+	CompletionContext   = "context"
+	CompletionNamespace = "namespace"
+	CompletionKind      = "kind"
+	CompletionName      = "name"
+)
+
+type Completion struct {
+	// Replaces the whole command bar input
+	Text string `json:"text"`
+	// One of the Completion* constants
+	Type   string `json:"type"`
+	Detail string `json:"detail"`
+	Score  int    `json:"score"`
+}
+
+// ScoreCompletion ranks how well candidate completes partial. Exact beats prefix beats substring,
+// and shorter candidates win ties within each.
+func ScoreCompletion(candidate string, partial string) (int, bool) {
+	c := strings.ToLower(candidate)
+	p := strings.ToLower(partial)
+
+	var base int
+	switch {
+	case p == "":
+		base = 100
+	case c == p:
+		base = 300
+	case strings.HasPrefix(c, p):
+		base = 200
+	case strings.Contains(c, p):
+		base = 100
+	default:
+		return 0, false
+	}
+	return base - min(len(c), 99), true
+}
+
+// SortCompletions orders by score, then text, and keeps the first limit.
+func SortCompletions(cs []Completion, limit int) []Completion {
+	sort.SliceStable(cs, func(i, j int) bool {
+		if cs[i].Score != cs[j].Score {
+			return cs[i].Score > cs[j].Score
+		}
+		return cs[i].Text < cs[j].Text
+	})
+	if len(cs) > limit {
+		cs = cs[:limit]
+	}
+	return cs
+}
+
+// CompleteKinds matches partial against every way a kind can be named in a query: plural,
+// singular, short names, kind and categories.
+func (kc *KubeCluster) CompleteKinds(partial string) []Completion {
+	best := map[string]Completion{}
+	add := func(text string, detail string) {
+		if text == "" {
+			return
+		}
+		score, ok := ScoreCompletion(text, partial)
+		if !ok {
+			return
+		}
+		if existing, found := best[text]; !found || existing.Score < score {
+			best[text] = Completion{Text: text, Type: CompletionKind, Detail: detail, Score: score}
+		}
+	}
+
+	for _, r := range kc.apiResources {
+		detail := fmt.Sprintf("%s %s", r.Kind, toGV(r).String())
+		add(r.Name, detail)
+		add(r.SingularName, detail)
+		add(strings.ToLower(r.Kind), detail)
+		for _, sn := range r.ShortNames {
+			add(sn, detail)
+		}
+		for _, c := range r.Categories {
+			add(c, "category")
+		}
+	}
+
+	cs := make([]Completion, 0, len(best))
+	for _, c := range best {
+		cs = append(cs, c)
+	}
+	return cs
+}
+
+// CompleteQuery completes the last comma separated term of a query, a kind or a kind/name, and
+// keeps the terms before it, so `deploy,svc/we` completes the names of services.
+func (kc *KubeCluster) CompleteQuery(ctx context.Context, nsName string, partial string) ([]Completion, error) {
+	i := strings.LastIndex(partial, ",")
+	terms, last := partial[:i+1], partial[i+1:]
+
+	var cs []Completion
+	var err error
+	if kind, name, isName := strings.Cut(last, "/"); isName {
+		cs, err = kc.CompleteNames(ctx, nsName, kind, name)
+	} else {
+		cs = kc.CompleteKinds(last)
+	}
+	for j := range cs {
+		cs[j].Text = terms + cs[j].Text
+	}
+	return cs, err
+}
+
+// CompleteNames completes kind/name forms from a cached list of kind's objects.
+func (kc *KubeCluster) CompleteNames(ctx context.Context, nsName string, kind string, partial string) ([]Completion, error) {
+	matches := findAPIResourcesFuzzy(kc.apiResources, kind)
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("no resources match %s", kind)
+	}
+	ar := matches[0]
+
+	names, err := kc.cachedNames(ctx, ar, nsName)
+	if err != nil {
+		return nil, err
+	}
+
+	cs := make([]Completion, 0)
+	for _, name := range names {
+		if score, ok := ScoreCompletion(name, partial); ok {
+			cs = append(cs, Completion{
+				Text:   fmt.Sprintf("%s/%s", kind, name),
+				Type:   CompletionName,
+				Detail: ar.Kind,
+				Score:  score,
+			})
+		}
+	}
+	return cs, nil
+}
+
+func (kc *KubeCluster) CompleteNamespaces(ctx context.Context, partial string) ([]Completion, error) {
+	nss, err := kc.names.get("namespaces", func() ([]string, error) {
+		return kc.KubeNamespaceList(ctx)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	cs := make([]Completion, 0)
+	for _, ns := range nss {
+		if score, ok := ScoreCompletion(ns, partial); ok {
+			cs = append(cs, Completion{Text: "ns " + ns, Type: CompletionNamespace, Score: score})
+		}
+	}
+	return cs, nil
+}
+
+func (kc *KubeCluster) cachedNames(ctx context.Context, ar metav1.APIResource, nsName string) ([]string, error) {
+	if !ar.Namespaced {
+		nsName = ""
+	}
+	key := fmt.Sprintf("%s/%s", toGVR(ar).String(), nsName)
+
+	return kc.names.get(key, func() ([]string, error) {
+		var ri dynamic.ResourceInterface = kc.dynamicClient.Resource(toGVR(ar))
+		if ar.Namespaced {
+			ri = kc.dynamicClient.Resource(toGVR(ar)).Namespace(nsName)
+		}
+
+		uList, err := ri.List(ctx, metav1.ListOptions{Limit: LIST_LIMIT})
+		if err != nil {
+			return nil, fmt.Errorf("unable to list %s: %w", ar.Name, err)
+		}
+
+		names := make([]string, len(uList.Items))
+		for i, item := range uList.Items {
+			names[i] = item.GetName()
+		}
+		return names, nil
+	})
+}
+
+// nameCache keeps lists of names for COMPLETION_CACHE_TTL.
+type nameCache struct {
+	lock    sync.Mutex
+	entries map[string]nameCacheEntry
+}
+
+type nameCacheEntry struct {
+	names   []string
+	fetched time.Time
+}
+
+func (c *nameCache) get(key string, fetch func() ([]string, error)) ([]string, error) {
+	c.lock.Lock()
+	entry, found := c.entries[key]
+	c.lock.Unlock()
+	if found && time.Since(entry.fetched) < COMPLETION_CACHE_TTL {
+		return entry.names, nil
+	}
+
+	names, err := fetch()
+	if err != nil {
+		return nil, err
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.entries == nil {
+		c.entries = map[string]nameCacheEntry{}
+	}
+	c.entries[key] = nameCacheEntry{names: names, fetched: time.Now()}
+	return names, nil
+}
@@ -0,0 +1,85 @@
+package kube
+
+import (
+	"context"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	runtime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testAPIResources() []metav1.APIResource {
+	return []metav1.APIResource{
+		{Name: "pods", SingularName: "pod", Kind: "Pod", Version: "v1", Namespaced: true, ShortNames: []string{"po"}, Categories: []string{"all"}},
+		{Name: "services", SingularName: "service", Kind: "Service", Version: "v1", Namespaced: true, ShortNames: []string{"svc"}, Categories: []string{"all"}},
+		{Name: "secrets", SingularName: "secret", Kind: "Secret", Version: "v1", Namespaced: true},
+		{Name: "nodes", SingularName: "node", Kind: "Node", Version: "v1", ShortNames: []string{"no"}},
+		{Name: "deployments", SingularName: "deployment", Kind: "Deployment", Group: "apps", Version: "v1", Namespaced: true, ShortNames: []string{"deploy"}, Categories: []string{"all"}},
+		{Name: "replicasets", SingularName: "replicaset", Kind: "ReplicaSet", Group: "apps", Version: "v1", Namespaced: true, ShortNames: []string{"rs"}, Categories: []string{"all"}},
+		{Name: "ingresses", SingularName: "ingress", Kind: "Ingress", Group: "networking.k8s.io", Version: "v1", Namespaced: true, ShortNames: []string{"ing"}},
+		{Name: "certificates", SingularName: "certificate", Kind: "Certificate", Group: "cert-manager.io", Version: "v1", Namespaced: true, ShortNames: []string{"cert", "certs"}, Categories: []string{"cert-manager"}},
+		{Name: "certificatesigningrequests", SingularName: "certificatesigningrequest", Kind: "CertificateSigningRequest", Group: "certificates.k8s.io", Version: "v1", ShortNames: []string{"csr"}},
+		{Name: "deployments", SingularName: "deployment", Kind: "Deployment", Group: "example.com", Version: "v1alpha1", Namespaced: true},
+	}
+}
+
+func TestScoreCompletion(t *testing.T) {
+	exact, ok := ScoreCompletion("svc", "svc")
+	assert.True(t, ok)
+	prefix, ok := ScoreCompletion("services", "se")
+	assert.True(t, ok)
+	substring, ok := ScoreCompletion("services", "vic")
+	assert.True(t, ok)
+	_, ok = ScoreCompletion("services", "pod")
+	assert.False(t, ok)
+
+	assert.Greater(t, exact, prefix)
+	assert.Greater(t, prefix, substring)
+
+	short, _ := ScoreCompletion("secret", "se")
+	assert.Greater(t, short, prefix)
+}
+
+func TestCompleteKinds(t *testing.T) {
+	kc := &KubeCluster{apiResources: testAPIResources()}
+
+	cs := SortCompletions(kc.CompleteKinds("se"), 3)
+	assert.Equal(t, []string{"secret", "secrets", "service"}, completionTexts(cs))
+	assert.Equal(t, "Secret v1", cs[0].Detail)
+
+	cs = SortCompletions(kc.CompleteKinds("all"), 10)
+	assert.Equal(t, []string{"all"}, completionTexts(cs))
+	assert.Equal(t, CompletionKind, cs[0].Type)
+}
+
+func TestCompleteQuery(t *testing.T) {
+	client := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		{Version: "v1", Resource: "services"}: "ServiceList",
+	}, testObject("v1", "Service", "web", nil), testObject("v1", "Service", "db", nil))
+	kc := &KubeCluster{apiResources: testAPIResources(), dynamicClient: client}
+
+	cs, err := kc.CompleteQuery(context.Background(), "shop", "deploy,svc/we")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"deploy,svc/web"}, completionTexts(cs))
+	assert.Equal(t, CompletionName, cs[0].Type)
+
+	cs, err = kc.CompleteQuery(context.Background(), "shop", "deploy,sv")
+	assert.NoError(t, err)
+	assert.Contains(t, completionTexts(cs), "deploy,svc")
+
+	cs, err = kc.CompleteQuery(context.Background(), "shop", "svc/d")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"svc/db"}, completionTexts(cs))
+}
+
+func completionTexts(cs []Completion) []string {
+	texts := make([]string, len(cs))
+	for i, c := range cs {
+		texts[i] = c.Text
+	}
+	return texts
+}
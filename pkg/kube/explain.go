@@ -0,0 +1,285 @@
+package kube
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/openapi"
+	"k8s.io/kube-openapi/pkg/spec3"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+)
+
+const gvkExtension = "x-kubernetes-group-version-kind"
+
+// FieldDoc describes a field of a kind, like kubectl explain.
+type FieldDoc struct {
+	Group   string `json:"group"`
+	Version string `json:"version"`
+	Kind    string `json:"kind"`
+	// e.g. .spec.template.spec.containers[].resources. Empty for the kind itself.
+	Path        string        `json:"path"`
+	Type        string        `json:"type"`
+	Description string        `json:"description"`
+	Required    bool          `json:"required"`
+	Enum        []interface{} `json:"enum"`
+	Default     interface{}   `json:"default"`
+	// The fields of an object, or of each item in a list of objects
+	Fields []FieldSummary `json:"fields"`
+}
+
+type FieldSummary struct {
+	Name        string `json:"name"`
+	Type        string `json:"type"`
+	Description string `json:"description"`
+	Required    bool   `json:"required"`
+}
+
+// openAPICache keeps the parsed OpenAPI v3 document of each group version. Fetching and parsing
+// one is slow and they only change when a CRD does.
+type openAPICache struct {
+	lock   sync.Mutex
+	client openapi.Client
+	docs   map[string]*spec3.OpenAPI
+}
+
+// get returns the document for gv. With refresh it's fetched again, for CRDs created or changed
+// since it was cached.
+func (c *openAPICache) get(gv schema.GroupVersion, refresh bool) (*spec3.OpenAPI, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	key := openAPIPath(gv)
+	if doc, found := c.docs[key]; found && !refresh {
+		return doc, nil
+	}
+
+	paths, err := c.client.Paths()
+	if err != nil {
+		return nil, fmt.Errorf("unable to get openapi paths: %w", err)
+	}
+	gvClient, found := paths[key]
+	if !found {
+		return nil, fmt.Errorf("no openapi schema for %s", gv)
+	}
+	bs, err := gvClient.Schema("application/json")
+	if err != nil {
+		return nil, fmt.Errorf("unable to get openapi schema for %s: %w", gv, err)
+	}
+	doc := &spec3.OpenAPI{}
+	if err := json.Unmarshal(bs, doc); err != nil {
+		return nil, fmt.Errorf("unable to parse openapi schema for %s: %w", gv, err)
+	}
+
+	if c.docs == nil {
+		c.docs = map[string]*spec3.OpenAPI{}
+	}
+	c.docs[key] = doc
+	return doc, nil
+}
+
+// openAPIPath is the discovery path of gv, e.g. api/v1 or apis/apps/v1.
+func openAPIPath(gv schema.GroupVersion) string {
+	if gv.Group == "" {
+		return "api/" + gv.Version
+	}
+	return "apis/" + gv.Group + "/" + gv.Version
+}
+
+func (kc *KubeCluster) openAPIDocs() (*openAPICache, error) {
+	kc.openAPI.lock.Lock()
+	defer kc.openAPI.lock.Unlock()
+	if kc.openAPI.client == nil {
+		client, err := discovery.NewDiscoveryClientForConfig(kc.restClientConfig)
+		if err != nil {
+			return nil, fmt.Errorf("unable to create new discovery client for config: %w", err)
+		}
+		kc.openAPI.client = client.OpenAPIV3()
+	}
+	return &kc.openAPI, nil
+}
+
+// Explain documents the field at path in the kind's preferred version. Path is dot separated and
+// list items may be written [], [0] or left out, so .spec.containers[].image,
+// .spec.containers[0].image and spec.containers.image are the same field.
+func (kc *KubeCluster) Explain(group string, kind string, path string) (*FieldDoc, error) {
+	matches := findAPIResources(kc.apiResources, group, kind)
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("unable to find an api resource: %s", kind)
+	}
+	gvk := toGVK(matches[0])
+
+	docs, err := kc.openAPIDocs()
+	if err != nil {
+		return nil, err
+	}
+	doc, err := docs.get(gvk.GroupVersion(), false)
+	if err != nil {
+		return nil, err
+	}
+	root := findKindSchema(doc, gvk)
+	if root == nil {
+		// The CRD may be newer than the cached schema
+		if doc, err = docs.get(gvk.GroupVersion(), true); err != nil {
+			return nil, err
+		}
+		if root = findKindSchema(doc, gvk); root == nil {
+			return nil, fmt.Errorf("no openapi schema for %s", gvk)
+		}
+	}
+	return explainField(doc, gvk, root, path)
+}
+
+// findKindSchema is the component schema tagged with gvk.
+func findKindSchema(doc *spec3.OpenAPI, gvk schema.GroupVersionKind) *spec.Schema {
+	if doc.Components == nil {
+		return nil
+	}
+	for _, s := range doc.Components.Schemas {
+		gvks, _ := s.Extensions[gvkExtension].([]interface{})
+		for _, v := range gvks {
+			m, _ := v.(map[string]interface{})
+			if m["group"] == gvk.Group && m["version"] == gvk.Version && m["kind"] == gvk.Kind {
+				return s
+			}
+		}
+	}
+	return nil
+}
+
+func explainField(doc *spec3.OpenAPI, gvk schema.GroupVersionKind, root *spec.Schema, path string) (*FieldDoc, error) {
+	fieldDoc := &FieldDoc{
+		Group:   gvk.Group,
+		Version: gvk.Version,
+		Kind:    gvk.Kind,
+		Path:    path,
+	}
+
+	// The field's own schema has its description and default. The resolved schema has its type.
+	field := root
+	resolved := resolveSchema(doc, root)
+	for _, name := range explainPath(path) {
+		object := itemSchema(doc, resolved)
+		prop, found := object.Properties[name]
+		if !found {
+			return nil, fmt.Errorf("%s has no field %s", gvk.Kind, strings.TrimPrefix(path, "."))
+		}
+		fieldDoc.Required = slices.Contains(object.Required, name)
+		field = &prop
+		resolved = resolveSchema(doc, field)
+	}
+
+	fieldDoc.Type = schemaType(doc, field)
+	if field == root {
+		fieldDoc.Type = gvk.Kind
+	}
+	fieldDoc.Description = field.Description
+	if fieldDoc.Description == "" {
+		fieldDoc.Description = resolved.Description
+	}
+	fieldDoc.Enum = resolved.Enum
+	fieldDoc.Default = field.Default
+	if fieldDoc.Default == nil {
+		fieldDoc.Default = resolved.Default
+	}
+
+	object := itemSchema(doc, resolved)
+	fieldDoc.Fields = make([]FieldSummary, 0, len(object.Properties))
+	for name, prop := range object.Properties {
+		description := prop.Description
+		if description == "" {
+			description = resolveSchema(doc, &prop).Description
+		}
+		fieldDoc.Fields = append(fieldDoc.Fields, FieldSummary{
+			Name:        name,
+			Type:        schemaType(doc, &prop),
+			Description: description,
+			Required:    slices.Contains(object.Required, name),
+		})
+	}
+	sort.Slice(fieldDoc.Fields, func(i, j int) bool {
+		return fieldDoc.Fields[i].Name < fieldDoc.Fields[j].Name
+	})
+	return fieldDoc, nil
+}
+
+// explainPath splits path into field names, dropping list indexes.
+func explainPath(path string) []string {
+	names := make([]string, 0)
+	for _, part := range strings.Split(path, ".") {
+		if i := strings.Index(part, "["); i != -1 {
+			part = part[:i]
+		}
+		if part != "" {
+			names = append(names, part)
+		}
+	}
+	return names
+}
+
+// resolveSchema follows $ref, including the allOf with a single $ref used for fields with their
+// own description or default.
+func resolveSchema(doc *spec3.OpenAPI, s *spec.Schema) *spec.Schema {
+	for i := 0; i < 10; i++ {
+		if len(s.AllOf) == 1 && len(s.Properties) == 0 && s.Type == nil {
+			s = &s.AllOf[0]
+			continue
+		}
+		ref := s.Ref.String()
+		if ref == "" || doc.Components == nil {
+			return s
+		}
+		target, found := doc.Components.Schemas[strings.TrimPrefix(ref, "#/components/schemas/")]
+		if !found {
+			return s
+		}
+		s = target
+	}
+	return s
+}
+
+// itemSchema is the schema of each item when s is a list, otherwise s.
+func itemSchema(doc *spec3.OpenAPI, s *spec.Schema) *spec.Schema {
+	if s.Type.Contains("array") && s.Items != nil && s.Items.Schema != nil {
+		return resolveSchema(doc, s.Items.Schema)
+	}
+	return s
+}
+
+// schemaType names the type like kubectl explain, e.g. string, []Container, map[string]string or
+// ObjectMeta.
+func schemaType(doc *spec3.OpenAPI, s *spec.Schema) string {
+	name := refName(s)
+	resolved := resolveSchema(doc, s)
+	switch {
+	case resolved.Type.Contains("array") && resolved.Items != nil && resolved.Items.Schema != nil:
+		return "[]" + schemaType(doc, resolved.Items.Schema)
+	case resolved.Type.Contains("object") && resolved.AdditionalProperties != nil && resolved.AdditionalProperties.Schema != nil:
+		return "map[string]" + schemaType(doc, resolved.AdditionalProperties.Schema)
+	case name != "" && len(resolved.Type) == 0 || name != "" && resolved.Type.Contains("object"):
+		return name
+	case len(resolved.Type) > 0:
+		return resolved.Type[0]
+	case name != "":
+		return name
+	}
+	return "Object"
+}
+
+// refName is the last part of the referenced schema's name, e.g. Container for
+// io.k8s.api.core.v1.Container.
+func refName(s *spec.Schema) string {
+	ref := s.Ref.String()
+	if ref == "" && len(s.AllOf) == 1 {
+		ref = s.AllOf[0].Ref.String()
+	}
+	if ref == "" {
+		return ""
+	}
+	return ref[strings.LastIndex(ref, ".")+1:]
+}
@@ -0,0 +1,138 @@
+package kube
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/openapi/openapitest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testExplainCluster() *KubeCluster {
+	kc := &KubeCluster{
+		apiResources: []metav1.APIResource{
+			{Name: "deployments", Kind: "Deployment", Group: "apps", Version: "v1", Namespaced: true},
+			{Name: "pods", Kind: "Pod", Version: "v1", Namespaced: true},
+			{Name: "widgets", Kind: "Widget", Group: "example.com", Version: "v1", Namespaced: true},
+		},
+	}
+	kc.openAPI.client = openapitest.NewEmbeddedFileClient()
+	return kc
+}
+
+func fieldNames(doc *FieldDoc) []string {
+	names := make([]string, len(doc.Fields))
+	for i, f := range doc.Fields {
+		names[i] = f.Name
+	}
+	return names
+}
+
+func TestExplain(t *testing.T) {
+	kc := testExplainCluster()
+
+	doc, err := kc.Explain("apps", "Deployment", "")
+	assert.NoError(t, err)
+	assert.Equal(t, "Deployment", doc.Type)
+	assert.Contains(t, doc.Description, "Deployment enables declarative updates")
+	assert.Equal(t, []string{"apiVersion", "kind", "metadata", "spec", "status"}, fieldNames(doc))
+
+	doc, err = kc.Explain("apps", "Deployment", ".spec.template.spec.containers[].resources")
+	assert.NoError(t, err)
+	assert.Equal(t, "ResourceRequirements", doc.Type)
+	assert.False(t, doc.Required)
+	assert.Contains(t, fieldNames(doc), "limits")
+	limits := doc.Fields[1]
+	assert.Equal(t, "limits", limits.Name)
+	assert.Equal(t, "map[string]Quantity", limits.Type)
+
+	doc, err = kc.Explain("apps", "Deployment", "spec.template.spec.containers[0]")
+	assert.NoError(t, err)
+	assert.Equal(t, "[]Container", doc.Type)
+	assert.Contains(t, fieldNames(doc), "image")
+	for _, f := range doc.Fields {
+		if f.Name == "name" {
+			assert.True(t, f.Required)
+			assert.Equal(t, "string", f.Type)
+		}
+	}
+
+	doc, err = kc.Explain("apps", "Deployment", ".spec.selector")
+	assert.NoError(t, err)
+	assert.True(t, doc.Required)
+	assert.Equal(t, "LabelSelector", doc.Type)
+
+	doc, err = kc.Explain("", "Pod", ".spec.containers.imagePullPolicy")
+	assert.NoError(t, err)
+	assert.Equal(t, "string", doc.Type)
+	assert.Contains(t, doc.Description, "Image pull policy")
+	assert.Empty(t, doc.Fields)
+
+	doc, err = kc.Explain("", "Pod", ".spec.containers.ports.protocol")
+	assert.NoError(t, err)
+	assert.Equal(t, "TCP", doc.Default)
+
+	_, err = kc.Explain("apps", "Deployment", ".spec.nope")
+	assert.ErrorContains(t, err, "Deployment has no field spec.nope")
+
+	_, err = kc.Explain("example.com", "Widget", ".spec")
+	assert.ErrorContains(t, err, "no openapi schema for example.com/v1")
+}
+
+const widgetSchema = `{
+  "openapi": "3.0.0",
+  "info": {"title": "Kubernetes CRD Swagger", "version": "v0.1.0"},
+  "paths": {},
+  "components": {"schemas": {
+    "com.example.v1.Widget": {
+      "type": "object",
+      "x-kubernetes-group-version-kind": [{"group": "example.com", "kind": "Widget", "version": "v1"}],
+      "properties": {
+        "spec": {
+          "type": "object",
+          "required": ["size"],
+          "properties": {
+            "size": {"type": "string", "description": "How big", "enum": ["small", "large"], "default": "small"},
+            "replicas": {"type": "integer", "format": "int32"}
+          }
+        }
+      }
+    }
+  }}
+}`
+
+func TestExplainCRD(t *testing.T) {
+	client := openapitest.NewFakeClient()
+	kc := &KubeCluster{
+		apiResources: []metav1.APIResource{
+			{Name: "widgets", Kind: "Widget", Group: "example.com", Version: "v1", Namespaced: true},
+		},
+	}
+	kc.openAPI.client = client
+
+	// Installed before the CRD
+	client.PathsMap["apis/example.com/v1"] = openapitest.FakeGroupVersion{GVSpec: []byte(`{"openapi": "3.0.0", "paths": {}, "components": {"schemas": {}}}`)}
+	_, err := kc.Explain("example.com", "Widget", ".spec")
+	assert.ErrorContains(t, err, "no openapi schema for example.com/v1, Kind=Widget")
+
+	client.PathsMap["apis/example.com/v1"] = openapitest.FakeGroupVersion{GVSpec: []byte(widgetSchema)}
+	doc, err := kc.Explain("example.com", "Widget", ".spec")
+	assert.NoError(t, err)
+	assert.Equal(t, []FieldSummary{
+		{Name: "replicas", Type: "integer"},
+		{Name: "size", Type: "string", Description: "How big", Required: true},
+	}, doc.Fields)
+
+	doc, err = kc.Explain("example.com", "Widget", ".spec.size")
+	assert.NoError(t, err)
+	assert.True(t, doc.Required)
+	assert.Equal(t, []interface{}{"small", "large"}, doc.Enum)
+	assert.Equal(t, "small", doc.Default)
+}
+
+func TestExplainPath(t *testing.T) {
+	assert.Equal(t, []string{}, explainPath(""))
+	assert.Equal(t, []string{"spec", "containers", "image"}, explainPath(".spec.containers[].image"))
+	assert.Equal(t, []string{"spec", "containers", "image"}, explainPath("spec.containers[3].image"))
+}
@@ -0,0 +1,220 @@
+package kube
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// Export formats. Tables export as CSV, TSV, Markdown or JSON. Resources export as multi document
+// YAML or a v1 List in YAML or JSON.
+const (
+	FormatCSV      = "csv"
+	FormatTSV      = "tsv"
+	FormatMarkdown = "markdown"
+	FormatJSON     = "json"
+	FormatYAML     = "yaml"
+	FormatList     = "list"
+)
+
+// ResourceRef identifies one object to export.
+type ResourceRef struct {
+	Namespace string `json:"namespace"`
+	Group     string `json:"group"`
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+}
+
+// FormatExtension is the file extension for an export format.
+func FormatExtension(format string) string {
+	switch format {
+	case FormatMarkdown:
+		return ".md"
+	case FormatYAML, FormatList:
+		return ".yaml"
+	}
+	return "." + format
+}
+
+// ExportTable renders the rows of rt, as displayed, in format.
+func ExportTable(rt ResourceTable, format string) (string, error) {
+	if rt.Table == nil {
+		return "", fmt.Errorf("no table to export")
+	}
+
+	header := make([]string, len(rt.Table.ColumnDefinitions))
+	for i, cd := range rt.Table.ColumnDefinitions {
+		header[i] = cd.Name
+	}
+	rows := make([][]string, len(rt.Table.Rows))
+	for i, row := range rt.Table.Rows {
+		rows[i] = make([]string, len(header))
+		for j := range header {
+			if j < len(row.Cells) && row.Cells[j] != nil {
+				rows[i][j] = exportCell(row.Cells[j])
+			}
+		}
+	}
+
+	switch format {
+	case FormatCSV:
+		return exportDelimited(header, rows, ',')
+	case FormatTSV:
+		return exportDelimited(header, rows, '\t')
+	case FormatMarkdown:
+		return exportMarkdown(header, rows), nil
+	case FormatJSON:
+		return exportJSONRows(header, rt.Table.Rows)
+	}
+	return "", fmt.Errorf("unknown table export format %s", format)
+}
+
+// exportCell is the displayed text of a cell. Cells from the frontend arrive with float64 numbers,
+// which fmt prints as 1e+06.
+func exportCell(cell interface{}) string {
+	if f, ok := cell.(float64); ok {
+		return strconv.FormatFloat(f, 'f', -1, 64)
+	}
+	return fmt.Sprint(cell)
+}
+
+func exportDelimited(header []string, rows [][]string, comma rune) (string, error) {
+	var b bytes.Buffer
+	w := csv.NewWriter(&b)
+	w.Comma = comma
+	if err := w.Write(header); err != nil {
+		return "", err
+	}
+	if err := w.WriteAll(rows); err != nil {
+		return "", fmt.Errorf("unable to write rows: %w", err)
+	}
+	return b.String(), nil
+}
+
+func exportMarkdown(header []string, rows [][]string) string {
+	var b strings.Builder
+	writeRow := func(cells []string) {
+		b.WriteString("|")
+		for _, c := range cells {
+			c = strings.ReplaceAll(c, "|", `\|`)
+			c = strings.ReplaceAll(c, "\n", " ")
+			fmt.Fprintf(&b, " %s |", c)
+		}
+		b.WriteString("\n")
+	}
+
+	writeRow(header)
+	b.WriteString("|")
+	for range header {
+		b.WriteString(" --- |")
+	}
+	b.WriteString("\n")
+	for _, row := range rows {
+		writeRow(row)
+	}
+	return b.String()
+}
+
+// exportJSONRows is an array of objects keyed by column name, keeping the column order and the
+// type of each cell.
+func exportJSONRows(header []string, rows []metav1.TableRow) (string, error) {
+	var b strings.Builder
+	b.WriteString("[")
+	for i, row := range rows {
+		if i > 0 {
+			b.WriteString(",")
+		}
+		b.WriteString("\n  {")
+		for j := range header {
+			var cell interface{}
+			if j < len(row.Cells) {
+				cell = row.Cells[j]
+			}
+			if j > 0 {
+				b.WriteString(", ")
+			}
+			key, err := json.Marshal(header[j])
+			if err != nil {
+				return "", err
+			}
+			value, err := json.Marshal(cell)
+			if err != nil {
+				return "", err
+			}
+			fmt.Fprintf(&b, "%s: %s", key, value)
+		}
+		b.WriteString("}")
+	}
+	if len(rows) > 0 {
+		b.WriteString("\n")
+	}
+	b.WriteString("]\n")
+	return b.String(), nil
+}
+
+// ExportResources fetches each ref and renders them together in format.
+func (kc *KubeCluster) ExportResources(ctx context.Context, refs []ResourceRef, format string) (string, error) {
+	objs := make([]*unstructured.Unstructured, 0, len(refs))
+	for _, ref := range refs {
+		matches := findAPIResources(kc.apiResources, ref.Group, ref.Kind)
+		if len(matches) == 0 {
+			return "", fmt.Errorf("unable to find an api resource: %s", ref.Kind)
+		}
+		u, err := kc.getResource(ctx, matches[0], ref.Namespace, ref.Name)
+		if err != nil {
+			return "", fmt.Errorf("unable to get %s %s/%s: %w", ref.Kind, ref.Namespace, ref.Name, err)
+		}
+		objs = append(objs, u)
+	}
+	return exportObjects(objs, format)
+}
+
+func exportObjects(objs []*unstructured.Unstructured, format string) (string, error) {
+	switch format {
+	case FormatYAML:
+		docs := make([]string, len(objs))
+		for i, u := range objs {
+			doc, err := renderYaml(u)
+			if err != nil {
+				return "", err
+			}
+			docs[i] = doc
+		}
+		return strings.Join(docs, "---\n"), nil
+
+	case FormatList, FormatJSON:
+		items := make([]interface{}, len(objs))
+		for i, u := range objs {
+			removeManagedFields(u)
+			items[i] = u.Object
+		}
+		list := map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "List",
+			"metadata":   map[string]interface{}{"resourceVersion": ""},
+			"items":      items,
+		}
+
+		var bs []byte
+		var err error
+		if format == FormatJSON {
+			bs, err = json.MarshalIndent(list, "", "  ")
+			bs = append(bs, '\n')
+		} else {
+			bs, err = yaml.Marshal(list)
+		}
+		if err != nil {
+			return "", fmt.Errorf("unable to marshal list: %w", err)
+		}
+		return string(bs), nil
+	}
+	return "", fmt.Errorf("unknown resource export format %s", format)
+}
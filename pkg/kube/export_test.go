@@ -0,0 +1,101 @@
+package kube
+
+import (
+	"encoding/json"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testExportTable() ResourceTable {
+	return ResourceTable{
+		Table: &metav1.Table{
+			ColumnDefinitions: []metav1.TableColumnDefinition{{Name: "Name"}, {Name: "Status"}, {Name: "Restarts"}},
+			Rows: []metav1.TableRow{
+				{Cells: []interface{}{"web-0", "Running", int64(0)}},
+				{Cells: []interface{}{"web-1", "Error, then|crash", int64(12)}},
+			},
+		},
+	}
+}
+
+func TestExportTable(t *testing.T) {
+	rt := testExportTable()
+
+	out, err := ExportTable(rt, FormatCSV)
+	assert.NoError(t, err)
+	assert.Equal(t, "Name,Status,Restarts\nweb-0,Running,0\nweb-1,\"Error, then|crash\",12\n", out)
+
+	out, err = ExportTable(rt, FormatTSV)
+	assert.NoError(t, err)
+	assert.Equal(t, "Name\tStatus\tRestarts\nweb-0\tRunning\t0\nweb-1\tError, then|crash\t12\n", out)
+
+	out, err = ExportTable(rt, FormatMarkdown)
+	assert.NoError(t, err)
+	assert.Equal(t, "| Name | Status | Restarts |\n| --- | --- | --- |\n| web-0 | Running | 0 |\n| web-1 | Error, then\\|crash | 12 |\n", out)
+
+	out, err = ExportTable(rt, FormatJSON)
+	assert.NoError(t, err)
+	assert.Equal(t, "[\n  {\"Name\": \"web-0\", \"Status\": \"Running\", \"Restarts\": 0},\n  {\"Name\": \"web-1\", \"Status\": \"Error, then|crash\", \"Restarts\": 12}\n]\n", out)
+
+	_, err = ExportTable(rt, "xml")
+	assert.Error(t, err)
+}
+
+func TestExportTableLargeNumbers(t *testing.T) {
+	rt := testExportTable()
+	rt.Table.Rows[0].Cells[2] = int64(1000000)
+
+	// As the table arrives from the frontend
+	data, err := json.Marshal(rt)
+	assert.NoError(t, err)
+	var fromFrontend ResourceTable
+	assert.NoError(t, json.Unmarshal(data, &fromFrontend))
+	assert.IsType(t, float64(0), fromFrontend.Table.Rows[0].Cells[2])
+
+	out, err := ExportTable(fromFrontend, FormatCSV)
+	assert.NoError(t, err)
+	assert.Equal(t, "Name,Status,Restarts\nweb-0,Running,1000000\nweb-1,\"Error, then|crash\",12\n", out)
+}
+
+func TestExportObjects(t *testing.T) {
+	objs := func() []*unstructured.Unstructured {
+		return []*unstructured.Unstructured{
+			{Object: map[string]interface{}{
+				"apiVersion": "v1",
+				"kind":       "ConfigMap",
+				"metadata": map[string]interface{}{
+					"name":          "a",
+					"managedFields": []interface{}{map[string]interface{}{"manager": "kubectl"}},
+				},
+			}},
+			{Object: map[string]interface{}{
+				"apiVersion": "v1",
+				"kind":       "ConfigMap",
+				"metadata":   map[string]interface{}{"name": "b"},
+			}},
+		}
+	}
+
+	out, err := exportObjects(objs(), FormatYAML)
+	assert.NoError(t, err)
+	assert.Equal(t, "apiVersion: v1\nkind: ConfigMap\nmetadata:\n    name: a\n---\napiVersion: v1\nkind: ConfigMap\nmetadata:\n    name: b\n", out)
+
+	out, err = exportObjects(objs(), FormatList)
+	assert.NoError(t, err)
+	assert.Contains(t, out, "kind: List\n")
+	assert.NotContains(t, out, "managedFields")
+
+	out, err = exportObjects(objs(), FormatJSON)
+	assert.NoError(t, err)
+	list := &unstructured.UnstructuredList{}
+	assert.NoError(t, json.Unmarshal([]byte(out), &list.Object))
+	assert.Equal(t, "List", list.Object["kind"])
+	assert.Len(t, list.Object["items"], 2)
+
+	_, err = exportObjects(objs(), FormatCSV)
+	assert.Error(t, err)
+}
@@ -0,0 +1,398 @@
+package kube
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// Filter is a parsed row filter expression, e.g. `Status != Running && Restarts > 3`.
+//
+//	expr       := and ("||" and)*
+//	and        := unary ("&&" unary)*
+//	unary      := "!" unary | "(" expr ")" | comparison
+//	comparison := column op value
+//	op         := "==" | "=" | "!=" | "<" | "<=" | ">" | ">=" | "=~" | "!~"
+//
+// Columns match case insensitively and may use underscores or quotes for spaces. Values are
+// compared using the column's type, so `Age < 1h`, `Restarts > 3` and `Memory >= 1Gi` work.
+type Filter struct {
+	root filterNode
+	expr string
+}
+
+type filterNode interface {
+	match(rt *ResourceTable, row int) bool
+}
+
+type andNode struct{ left, right filterNode }
+type orNode struct{ left, right filterNode }
+type notNode struct{ node filterNode }
+
+type comparisonNode struct {
+	column string
+	op     string
+	value  string
+	re     *regexp.Regexp
+}
+
+func (n andNode) match(rt *ResourceTable, row int) bool {
+	return n.left.match(rt, row) && n.right.match(rt, row)
+}
+
+func (n orNode) match(rt *ResourceTable, row int) bool {
+	return n.left.match(rt, row) || n.right.match(rt, row)
+}
+
+func (n notNode) match(rt *ResourceTable, row int) bool {
+	return !n.node.match(rt, row)
+}
+
+// ParseFilter parses expr. An empty expression is a nil Filter, which matches every row.
+func ParseFilter(expr string) (*Filter, error) {
+	tokens, err := tokenizeFilter(expr)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, nil
+	}
+
+	p := &filterParser{tokens: tokens}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, fmt.Errorf("invalid filter %q: %w", expr, err)
+	}
+	if !p.done() {
+		return nil, fmt.Errorf("invalid filter %q: unexpected %s", expr, p.peek().text)
+	}
+	return &Filter{root: root, expr: expr}, nil
+}
+
+func (f *Filter) String() string {
+	return f.expr
+}
+
+// FilterResourceTable keeps the rows of rt that match f and returns how many there are. Tables
+// missing a column in f have no matches.
+func FilterResourceTable(rt *ResourceTable, f *Filter) int {
+	if rt.Table == nil {
+		return 0
+	}
+	if f == nil {
+		return len(rt.Table.Rows)
+	}
+
+	matches := make([]int, 0, len(rt.Table.Rows))
+	for i := range rt.Table.Rows {
+		if f.root.match(rt, i) {
+			matches = append(matches, i)
+		}
+	}
+	selectRows(rt, matches)
+	return len(matches)
+}
+
+func (n comparisonNode) match(rt *ResourceTable, row int) bool {
+	col := findColumn(rt.Table, n.column)
+	if col == -1 {
+		return false
+	}
+
+	var sortValue interface{}
+	if row < len(rt.SortValues) && col < len(rt.SortValues[row]) {
+		sortValue = rt.SortValues[row][col]
+	}
+	columnType := ColumnString
+	if col < len(rt.ColumnTypes) {
+		columnType = rt.ColumnTypes[col]
+	}
+
+	if columnType != ColumnString && n.re == nil {
+		switch {
+		case sortValue == nil:
+			// `<none>` isn't less or greater than a number. It may still equal a string.
+			if n.op != "==" && n.op != "=" && n.op != "!=" {
+				return false
+			}
+		default:
+			if cell, value, ok := typedOperands(columnType, sortValue, n.value); ok {
+				return compareFloats(cell, n.op, value)
+			}
+		}
+	}
+
+	var display string
+	if cells := rt.Table.Rows[row].Cells; col < len(cells) && cells[col] != nil {
+		display = fmt.Sprint(cells[col])
+	}
+	return n.matchString(display)
+}
+
+// typedOperands converts the cell and the filter value to comparable numbers. Durations are
+// seconds so `Age < 1h` is objects created in the last hour.
+func typedOperands(columnType string, sortValue interface{}, value string) (float64, float64, bool) {
+	switch columnType {
+	case ColumnDuration:
+		cell, ok := sortValue.(float64)
+		d, parsed := parseFilterDuration(value)
+		return cell, d, ok && parsed
+
+	case ColumnQuantity:
+		cell, ok := sortValue.(float64)
+		q, err := resource.ParseQuantity(value)
+		return cell, q.AsApproximateFloat64(), ok && err == nil
+
+	case ColumnNumber:
+		cell, ok := sortValue.(float64)
+		f, err := strconv.ParseFloat(value, 64)
+		return cell, f, ok && err == nil
+	}
+	return 0, 0, false
+}
+
+// parseFilterDuration accepts the displayed form, 5d3h, Go's form, 1h30m, or seconds.
+func parseFilterDuration(value string) (float64, bool) {
+	if seconds, ok := parseDuration(value); ok {
+		return seconds.(float64), true
+	}
+	if d, err := time.ParseDuration(value); err == nil {
+		return d.Seconds(), true
+	}
+	f, err := strconv.ParseFloat(value, 64)
+	return f, err == nil
+}
+
+func compareFloats(cell float64, op string, value float64) bool {
+	switch op {
+	case "==", "=":
+		return cell == value
+	case "!=":
+		return cell != value
+	case "<":
+		return cell < value
+	case "<=":
+		return cell <= value
+	case ">":
+		return cell > value
+	case ">=":
+		return cell >= value
+	}
+	return false
+}
+
+func (n comparisonNode) matchString(display string) bool {
+	switch n.op {
+	case "==", "=":
+		return strings.EqualFold(display, n.value)
+	case "!=":
+		return !strings.EqualFold(display, n.value)
+	case "=~":
+		return n.re.MatchString(display)
+	case "!~":
+		return !n.re.MatchString(display)
+	}
+
+	cmp := strings.Compare(strings.ToLower(display), strings.ToLower(n.value))
+	switch n.op {
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	case ">":
+		return cmp > 0
+	case ">=":
+		return cmp >= 0
+	}
+	return false
+}
+
+type filterTokenKind int
+
+const (
+	tokenWord filterTokenKind = iota
+	tokenOp
+	tokenAnd
+	tokenOr
+	tokenNot
+	tokenOpen
+	tokenClose
+)
+
+type filterToken struct {
+	kind filterTokenKind
+	text string
+}
+
+var filterOps = []string{"==", "!=", "<=", ">=", "=~", "!~", "<", ">", "="}
+
+func tokenizeFilter(expr string) ([]filterToken, error) {
+	tokens := make([]filterToken, 0)
+	rs := []rune(expr)
+	for i := 0; i < len(rs); {
+		r := rs[i]
+		rest := string(rs[i:])
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case strings.HasPrefix(rest, "&&"):
+			tokens = append(tokens, filterToken{tokenAnd, "&&"})
+			i += 2
+		case strings.HasPrefix(rest, "||"):
+			tokens = append(tokens, filterToken{tokenOr, "||"})
+			i += 2
+		case r == '(':
+			tokens = append(tokens, filterToken{tokenOpen, "("})
+			i++
+		case r == ')':
+			tokens = append(tokens, filterToken{tokenClose, ")"})
+			i++
+		case r == '"' || r == '\'':
+			end := strings.IndexRune(string(rs[i+1:]), r)
+			if end == -1 {
+				return nil, fmt.Errorf("unterminated quote in filter %q", expr)
+			}
+			word := string(rs[i+1:])[:end]
+			tokens = append(tokens, filterToken{tokenWord, word})
+			i += len([]rune(word)) + 2
+		default:
+			if op := filterOpAt(rest); op != "" {
+				tokens = append(tokens, filterToken{tokenOp, op})
+				i += len(op)
+				continue
+			}
+			if r == '!' {
+				tokens = append(tokens, filterToken{tokenNot, "!"})
+				i++
+				continue
+			}
+
+			start := i
+			for i < len(rs) && !unicode.IsSpace(rs[i]) && !strings.ContainsRune(`()!=<>&|"'`, rs[i]) {
+				i++
+			}
+			if i == start {
+				return nil, fmt.Errorf("unexpected %q in filter %q", r, expr)
+			}
+			tokens = append(tokens, filterToken{tokenWord, string(rs[start:i])})
+		}
+	}
+	return tokens, nil
+}
+
+func filterOpAt(s string) string {
+	for _, op := range filterOps {
+		if strings.HasPrefix(s, op) {
+			return op
+		}
+	}
+	return ""
+}
+
+type filterParser struct {
+	tokens []filterToken
+	pos    int
+}
+
+func (p *filterParser) done() bool {
+	return p.pos >= len(p.tokens)
+}
+
+func (p *filterParser) peek() filterToken {
+	if p.done() {
+		return filterToken{kind: -1, text: "end of filter"}
+	}
+	return p.tokens[p.pos]
+}
+
+func (p *filterParser) next() filterToken {
+	t := p.peek()
+	p.pos++
+	return t
+}
+
+func (p *filterParser) parseOr() (filterNode, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().kind == tokenOr {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = orNode{left, right}
+	}
+	return left, nil
+}
+
+func (p *filterParser) parseAnd() (filterNode, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().kind == tokenAnd {
+		p.next()
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = andNode{left, right}
+	}
+	return left, nil
+}
+
+func (p *filterParser) parseUnary() (filterNode, error) {
+	switch p.peek().kind {
+	case tokenNot:
+		p.next()
+		node, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return notNode{node}, nil
+
+	case tokenOpen:
+		p.next()
+		node, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if t := p.next(); t.kind != tokenClose {
+			return nil, fmt.Errorf("expected ) but found %s", t.text)
+		}
+		return node, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *filterParser) parseComparison() (filterNode, error) {
+	column := p.next()
+	if column.kind != tokenWord {
+		return nil, fmt.Errorf("expected a column but found %s", column.text)
+	}
+	op := p.next()
+	if op.kind != tokenOp {
+		return nil, fmt.Errorf("expected a comparison after %s but found %s", column.text, op.text)
+	}
+	value := p.next()
+	if value.kind != tokenWord {
+		return nil, fmt.Errorf("expected a value after %s %s but found %s", column.text, op.text, value.text)
+	}
+
+	n := comparisonNode{column: column.text, op: op.text, value: value.text}
+	if op.text == "=~" || op.text == "!~" {
+		re, err := regexp.Compile("(?i)" + value.text)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern %s: %w", value.text, err)
+		}
+		n.re = re
+	}
+	return n, nil
+}
@@ -0,0 +1,93 @@
+package kube
+
+import (
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testPodTable() *ResourceTable {
+	now := time.Now()
+	table := &metav1.Table{
+		ColumnDefinitions: []metav1.TableColumnDefinition{
+			{Name: "Name"}, {Name: "Status"}, {Name: "Restarts"}, {Name: "Memory"}, {Name: "Nominated Node"}, {Name: "Age"},
+		},
+		Rows: []metav1.TableRow{
+			{Cells: []interface{}{"web-0", "Running", "0", "128Mi", "<none>", "5d"}},
+			{Cells: []interface{}{"web-1", "CrashLoopBackOff", "12 (1m ago)", "2Gi", "<none>", "30m"}},
+			{Cells: []interface{}{"web-2", "Pending", "4", "1Gi", "node-a", "10m"}},
+			{Cells: []interface{}{"db-0", "Running", "5 (3h ago)", "4Gi", "<none>", "2h"}},
+			{Cells: []interface{}{"job-0", "Completed", "<none>", "64Mi", "<none>", "3d"}},
+		},
+	}
+	ages := []time.Duration{5 * 24 * time.Hour, 30 * time.Minute, 10 * time.Minute, 2 * time.Hour, 3 * 24 * time.Hour}
+	names := make([]string, len(table.Rows))
+	for i, age := range ages {
+		u := &unstructured.Unstructured{Object: map[string]interface{}{}}
+		u.SetName(table.Rows[i].Cells[0].(string))
+		u.SetCreationTimestamp(metav1.NewTime(now.Add(-age)))
+		table.Rows[i].Object = rowObject(u)
+		names[i] = u.GetName()
+	}
+
+	values, types := sortValues(table)
+	return &ResourceTable{Table: table, TableRowNames: names, SortValues: values, ColumnTypes: types}
+}
+
+func TestFilterResourceTable(t *testing.T) {
+	tests := []struct {
+		expr     string
+		expected []string
+	}{
+		{"", []string{"web-0", "web-1", "web-2", "db-0", "job-0"}},
+		{"Status != Running && Restarts > 3", []string{"web-1", "web-2"}},
+		{"status == running", []string{"web-0", "db-0"}},
+		{"Age < 1h", []string{"web-1", "web-2"}},
+		{"Age >= 1d", []string{"web-0", "job-0"}},
+		{"Memory >= 1Gi && !(Status = Pending)", []string{"web-1", "db-0"}},
+		{"Restarts > 10 || Name =~ ^db", []string{"web-1", "db-0"}},
+		{"Name !~ web", []string{"db-0", "job-0"}},
+		// <none> is neither less nor greater than a number, but still compares as a string
+		{"Restarts <= 5", []string{"web-0", "web-2", "db-0"}},
+		{"Restarts < 1 || Restarts >= 1", []string{"web-0", "web-1", "web-2", "db-0"}},
+		{"Restarts == '<none>'", []string{"job-0"}},
+		{"Restarts != 0", []string{"web-1", "web-2", "db-0", "job-0"}},
+		{"nominated_node == node-a", []string{"web-2"}},
+		{`"Nominated Node" != '<none>'`, []string{"web-2"}},
+		{"Missing == x", []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			f, err := ParseFilter(tt.expr)
+			assert.NoError(t, err)
+
+			rt := testPodTable()
+			matches := FilterResourceTable(rt, f)
+			assert.Equal(t, tt.expected, rt.TableRowNames)
+			assert.Equal(t, len(tt.expected), matches)
+			assert.Len(t, rt.Table.Rows, matches)
+			assert.Len(t, rt.SortValues, matches)
+		})
+	}
+}
+
+func TestParseFilterErrors(t *testing.T) {
+	for _, expr := range []string{
+		"Status",
+		"Status ==",
+		"== Running",
+		"(Status == Running",
+		"Status == Running)",
+		"Status == Running &&",
+		`Name == "web`,
+		"Name =~ (",
+	} {
+		_, err := ParseFilter(expr)
+		assert.Error(t, err, expr)
+	}
+}
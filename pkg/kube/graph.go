@@ -0,0 +1,138 @@
+package kube
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"bosun/pkg/kube/relations"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const (
+	GRAPH_MAX_DEPTH = 5
+	GRAPH_MAX_NODES = 500
+)
+
+// RelationGraph follows HasOne references from a single object out to depth hops.
+func (kc *KubeCluster) RelationGraph(ctx context.Context, nsName string, group string, kind string, name string, depth int) (*relations.Graph, error) {
+	matches := findAPIResources(kc.apiResources, group, kind)
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("unable to find an api resource: %s", kind)
+	}
+	ar := matches[0]
+
+	namespace := nsName
+	if !ar.Namespaced {
+		namespace = ""
+	}
+
+	g := relations.MakeGraph()
+	start, _ := g.AddNode(ar.Group, ar.Version, ar.Kind, namespace, name)
+	kc.walkGraph(ctx, g, []*relations.Node{start}, nil, depth)
+	g.Render()
+	return g, nil
+}
+
+// NamespaceRelationGraph starts from every object in the namespace whose kind is in the "all"
+// category, the same set as `kubectl get all`.
+func (kc *KubeCluster) NamespaceRelationGraph(ctx context.Context, nsName string, depth int) (*relations.Graph, error) {
+	g := relations.MakeGraph()
+	starts := make([]*relations.Node, 0)
+	listed := map[string]*unstructured.Unstructured{}
+
+	for _, ar := range kc.apiResources {
+		if !ar.Namespaced || !slices.Contains(ar.Categories, "all") {
+			continue
+		}
+
+		uList, err := kc.dynamicClient.Resource(toGVR(ar)).Namespace(nsName).List(ctx, metav1.ListOptions{Limit: LIST_LIMIT})
+		if err != nil {
+			log.Error("NamespaceRelationGraph list failed", "resource", ar.Name, "error", err)
+			continue
+		}
+
+		for i := range uList.Items {
+			item := &uList.Items[i]
+			n, _ := g.AddNode(ar.Group, ar.Version, ar.Kind, item.GetNamespace(), item.GetName())
+			starts = append(starts, n)
+			listed[n.Id] = item
+		}
+	}
+
+	kc.walkGraph(ctx, g, starts, listed, depth)
+	g.Render()
+	return g, nil
+}
+
+// walkGraph is a breadth first search from starts. Objects already listed, by node id, aren't
+// fetched again. Objects that can't be fetched stay in the graph as missing nodes. The graph is
+// Truncated when it reaches GRAPH_MAX_NODES.
+func (kc *KubeCluster) walkGraph(ctx context.Context, g *relations.Graph, starts []*relations.Node, listed map[string]*unstructured.Unstructured, depth int) {
+	depth = min(max(depth, 0), GRAPH_MAX_DEPTH)
+
+	frontier := starts
+	for hop := 0; hop < depth && len(frontier) > 0; hop++ {
+		next := make([]*relations.Node, 0)
+
+		for _, n := range frontier {
+			refs, err := kc.nodeReferences(ctx, n, listed[n.Id])
+			if err != nil {
+				log.Info("walkGraph unable to get references", "node", n.Id, "error", err)
+				n.Missing = true
+				continue
+			}
+
+			for _, ref := range refs {
+				if ref.RelationType != relations.HasOne {
+					continue
+				}
+				if len(g.Nodes) >= GRAPH_MAX_NODES {
+					g.Truncated = true
+					return
+				}
+
+				to, added := g.AddNode(ref.Group, ref.Version, ref.Kind, kc.refNamespace(ref, n.Namespace), ref.Name)
+				g.AddEdge(n, to, ref)
+				if added {
+					next = append(next, to)
+				}
+			}
+		}
+
+		frontier = next
+	}
+}
+
+// nodeReferences of u, or of the node's object fetched when u is nil.
+func (kc *KubeCluster) nodeReferences(ctx context.Context, n *relations.Node, u *unstructured.Unstructured) ([]relations.Reference, error) {
+	if u != nil {
+		return kc.references(u)
+	}
+
+	matches := findAPIResources(kc.apiResources, n.Group, n.Kind)
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("unable to find an api resource: %s", n.Kind)
+	}
+
+	u, err := kc.getResource(ctx, matches[0], n.Namespace, n.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	return kc.references(u)
+}
+
+// refNamespace fills in the namespace references leave implicit, e.g. ownerReferences.
+func (kc *KubeCluster) refNamespace(ref relations.Reference, fromNamespace string) string {
+	matches := findAPIResources(kc.apiResources, ref.Group, ref.Kind)
+	if len(matches) > 0 && !matches[0].Namespaced {
+		return ""
+	}
+	if ref.Namespace == "" {
+		return fromNamespace
+	}
+	return ref.Namespace
+}
@@ -0,0 +1,86 @@
+package kube
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"bosun/pkg/kube/relations"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	runtime "k8s.io/apimachinery/pkg/runtime"
+	k8stesting "k8s.io/client-go/testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func ownedConfigMap(name string, owners ...string) *unstructured.Unstructured {
+	u := testObject("v1", "ConfigMap", name, nil)
+	refs := make([]metav1.OwnerReference, len(owners))
+	for i, owner := range owners {
+		refs[i] = metav1.OwnerReference{APIVersion: "v1", Kind: "ConfigMap", Name: owner}
+	}
+	u.SetOwnerReferences(refs)
+	return u
+}
+
+func TestWalkGraphCycle(t *testing.T) {
+	kc, _ := testBackupCluster(ownedConfigMap("a", "b"), ownedConfigMap("b", "a"))
+
+	g, err := kc.RelationGraph(context.Background(), "shop", "", "ConfigMap", "a", GRAPH_MAX_DEPTH)
+	assert.NoError(t, err)
+	assert.False(t, g.Truncated)
+	// a, b and their namespace
+	assert.Len(t, g.Nodes, 3)
+	assert.Len(t, g.Edges, 4)
+}
+
+func TestWalkGraphMissing(t *testing.T) {
+	kc, _ := testBackupCluster(ownedConfigMap("a", "gone"))
+
+	g, err := kc.RelationGraph(context.Background(), "shop", "", "ConfigMap", "a", 2)
+	assert.NoError(t, err)
+	missing := make([]string, 0)
+	for _, n := range g.Nodes {
+		if n.Missing {
+			missing = append(missing, n.Id)
+		}
+	}
+	assert.Equal(t, []string{"/Namespace//shop", "/ConfigMap/shop/gone"}, missing)
+}
+
+func TestWalkGraphMaxNodes(t *testing.T) {
+	owners := make([]string, GRAPH_MAX_NODES+10)
+	for i := range owners {
+		owners[i] = fmt.Sprintf("owner-%d", i)
+	}
+	kc, _ := testBackupCluster(ownedConfigMap("a", owners...))
+
+	g, err := kc.RelationGraph(context.Background(), "shop", "", "ConfigMap", "a", 1)
+	assert.NoError(t, err)
+	assert.True(t, g.Truncated)
+	assert.Len(t, g.Nodes, GRAPH_MAX_NODES)
+	assert.Contains(t, g.Mermaid, "Truncated at 500 objects")
+}
+
+func TestNamespaceGraphUsesList(t *testing.T) {
+	kc, client := testBackupCluster(ownedConfigMap("a", "b"), ownedConfigMap("b"))
+	for i := range kc.apiResources {
+		kc.apiResources[i].Categories = []string{"all"}
+	}
+
+	gets := 0
+	client.PrependReactor("get", "*", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		gets++
+		return false, nil, nil
+	})
+
+	g, err := kc.NamespaceRelationGraph(context.Background(), "shop", 1)
+	assert.NoError(t, err)
+	assert.Contains(t, g.Edges, relations.Edge{
+		From: "/ConfigMap/shop/a", To: "/ConfigMap/shop/b", RelationType: relations.HasOne, Property: ".metadata.ownerReferences[0].name",
+	})
+	// Every object came from the list
+	assert.Equal(t, 0, gets)
+}
@@ -3,7 +3,6 @@ package kube
 import (
 	"context"
 	"fmt"
-	"slices"
 	"strings"
 	"sync"
 
@@ -16,6 +15,7 @@ import (
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/kubectl/pkg/describe"
@@ -26,13 +26,17 @@ var log = logging.Default()
 const LIST_LIMIT = 1000
 
 type Kubes struct {
-	lock        sync.RWMutex
-	ctxClusters map[string]*KubeCluster
+	lock          sync.RWMutex
+	ctxClusters   map[string]*KubeCluster
+	relationRules []relations.Rule
+	columnSets    []ColumnSet
 }
 
-func MakeKube() *Kubes {
+func MakeKube(relationRules []relations.Rule, columnSets []ColumnSet) *Kubes {
 	return &Kubes{
-		ctxClusters: map[string]*KubeCluster{},
+		ctxClusters:   map[string]*KubeCluster{},
+		relationRules: relationRules,
+		columnSets:    columnSets,
 	}
 }
 
@@ -43,7 +47,7 @@ func (k *Kubes) GetOrMakeKubeCluster(kubeCtxName string) (*KubeCluster, error) {
 	kc, found := k.ctxClusters[kubeCtxName]
 	if !found {
 		var err error
-		kc, err = NewKubeCluster(kubeCtxName)
+		kc, err = NewKubeCluster(kubeCtxName, k.relationRules, k.columnSets)
 		if err != nil {
 			return nil, err
 		}
@@ -58,68 +62,137 @@ type ResourceTable struct {
 	Table         *metav1.Table      `json:"table"`
 	IsError       bool               `json:"isError"`
 	TableRowNames []string           `json:"tableRowNames"`
+	// Raw values for sorting and filtering, one per cell, and the type of each column. See sortValues.
+	SortValues  [][]interface{} `json:"sortValues"`
+	ColumnTypes []string        `json:"columnTypes"`
+	// Rows matching QueryOptions.Filter, which is every row without one
+	Matches int `json:"matches"`
 }
 
+// Query lists the resources named by each comma separated term of query. Tables are returned in
+// the order of the terms. A category like `all` expands to many tables, and within it non-empty
+// tables come first.
 func (kc *KubeCluster) Query(ctx context.Context, nsName string, query string) ([]ResourceTable, error) {
-	log.Info("Query for", "query", query)
-	matches := findAPIResourcesFuzzy(kc.apiResources, query)
-	log.Info("matches found", "kinds", lo.Map(matches, func(ar metav1.APIResource, _ int) string { return ar.Kind }))
+	return kc.QueryWithOptions(ctx, nsName, query, QueryOptions{})
+}
 
-	results := lo.Map(matches, func(r metav1.APIResource, _ int) ResourceTable {
-		table, err := kc.listResource(ctx, r, nsName)
-		if err != nil {
-			log.Error("listResource error for resource", "resource", r, "error", err)
-			table = PrintError(err)
-		}
+// Selectors narrow every kind in a query, as in `kubectl get -l <label> --field-selector <field>`.
+type Selectors struct {
+	Label string `json:"label"`
+	Field string `json:"field"`
+}
 
-		// Get a list of metadata.name for the object represented by each row. Ideally this would come from
-		// Table.Rows[]Object but I'm not sure how to specify the includeObject policy or decode the RawExtension
-		// instance.
-		nameIdx := -1
-		for i, cd := range table.ColumnDefinitions {
-			if strings.ToLower(cd.Name) == "name" && cd.Type == "string" {
-				nameIdx = i
+type QueryOptions struct {
+	Selectors Selectors `json:"selectors"`
+	// Column to sort every table by. Tables without the column keep the server's order.
+	SortBy     string `json:"sortBy"`
+	Descending bool   `json:"descending"`
+	// Include the columns with a Priority above 0, like `kubectl get -o wide`
+	Wide bool `json:"wide"`
+	// Row filter expression. See Filter.
+	Filter string `json:"filter"`
+}
+
+// QueryWithOptions is Query with only the objects matching the selectors, sorted.
+func (kc *KubeCluster) QueryWithOptions(ctx context.Context, nsName string, query string, opts QueryOptions) ([]ResourceTable, error) {
+	log.Info("Query for", "query", query, "opts", opts)
+	filter, err := ParseFilter(opts.Filter)
+	if err != nil {
+		return nil, err
+	}
+	resolved := resolveQuery(kc.apiResources, ParseQuery(query))
+
+	orderedResults := make([]ResourceTable, 0)
+	for _, targets := range resolved {
+		log.Info("matches found", "kinds", lo.Map(targets, func(t queryTarget, _ int) string { return t.apiResource.Kind }))
+
+		results := lo.Map(targets, func(t queryTarget, _ int) ResourceTable {
+			rt := kc.queryTable(ctx, t, nsName, opts)
+			if !rt.IsError {
+				rt.Matches = FilterResourceTable(&rt, filter)
 			}
-		}
-		rowNames := make([]string, len(table.Rows))
-		for i, row := range table.Rows {
-			if nameIdx > -1 {
-				rowNames[i] = row.Cells[nameIdx].(string)
-			} else {
-				rowNames[i] = ""
+			if opts.SortBy != "" {
+				SortResourceTable(&rt, opts.SortBy, opts.Descending)
 			}
-		}
+			return rt
+		})
+
+		// Maintain order of the results, but move empty tables to the end
+		nonEmpty, empty := util.Partition(results, func(r ResourceTable) bool {
+			return len(r.Table.Rows) > 0
+		})
+		orderedResults = append(orderedResults, nonEmpty...)
+		orderedResults = append(orderedResults, empty...)
+	}
 
-		return ResourceTable{
-			APIResource:   r,
-			Table:         table,
-			IsError:       err != nil,
-			TableRowNames: rowNames,
-		}
-	})
+	return orderedResults, nil
+}
 
-	// Maintain order of the results, but move empty tables to the end
-	nonEmpty, empty := util.Partition(results, func(r ResourceTable) bool {
-		return len(r.Table.Rows) > 0
-	})
-	orderedResults := append(nonEmpty, empty...)
+func (kc *KubeCluster) queryTable(ctx context.Context, t queryTarget, nsName string, opts QueryOptions) ResourceTable {
+	r := t.apiResource
+	table, _, err := kc.listResource(ctx, r, nsName, t.name, opts)
+	if err != nil {
+		log.Error("listResource error for resource", "resource", r, "error", err)
+		table = PrintError(err)
+	}
 
-	return orderedResults, nil
+	// metadata.name for the object represented by each row
+	rowNames := make([]string, len(table.Rows))
+	for i, row := range table.Rows {
+		if m := RowMetadata(row); m != nil {
+			rowNames[i] = m.Name
+		}
+	}
+
+	values, types := sortValues(table)
+	return ResourceTable{
+		APIResource:   r,
+		Table:         table,
+		IsError:       err != nil,
+		TableRowNames: rowNames,
+		SortValues:    values,
+		ColumnTypes:   types,
+	}
 }
 
-func (kc *KubeCluster) listResource(ctx context.Context, r metav1.APIResource, namespace string) (*metav1.Table, error) {
+// listResource lists the objects of r matching the selectors, or only the one named name.
+func (kc *KubeCluster) listResource(ctx context.Context, r metav1.APIResource, namespace string, name string, queryOpts QueryOptions) (*metav1.Table, *unstructured.UnstructuredList, error) {
+	opts := metav1.ListOptions{
+		Limit:         LIST_LIMIT,
+		LabelSelector: queryOpts.Selectors.Label,
+		FieldSelector: queryOpts.Selectors.Field,
+	}
+	if name != "" {
+		byName := fields.OneTermEqualSelector("metadata.name", name).String()
+		if opts.FieldSelector == "" {
+			opts.FieldSelector = byName
+		} else {
+			opts.FieldSelector += "," + byName
+		}
+	}
+
 	var uList *unstructured.UnstructuredList
 	var err error
 	if r.Namespaced {
-		uList, err = kc.dynamicClient.Resource(toGVR(r)).Namespace(namespace).List(ctx, metav1.ListOptions{Limit: LIST_LIMIT})
+		uList, err = kc.dynamicClient.Resource(toGVR(r)).Namespace(namespace).List(ctx, opts)
 	} else {
-		uList, err = kc.dynamicClient.Resource(toGVR(r)).List(ctx, metav1.ListOptions{Limit: LIST_LIMIT})
+		uList, err = kc.dynamicClient.Resource(toGVR(r)).List(ctx, opts)
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("dynamicClient list failed for %+v: %w", r, err)
 	}
+
+	table, err := PrintList(kc.scheme, r, uList, queryOpts.Wide)
 	if err != nil {
-		return nil, fmt.Errorf("dynamicClient list failed for %+v: %w", r, err)
+		return nil, nil, err
 	}
 
-	return PrintList(kc.scheme, r, uList)
+	if cs, found := findColumnSet(kc.columnSets, r); found {
+		if err := applyColumnSet(table, cs, uList); err != nil {
+			log.Error("unable to apply columns", "groupKind", cs.GroupKind(), "error", err)
+		}
+	}
+	return table, uList, nil
 }
 
 func findAPIResources(apiResources []metav1.APIResource, group string, kind string) []metav1.APIResource {
@@ -134,28 +207,14 @@ func findAPIResources(apiResources []metav1.APIResource, group string, kind stri
 	return lo.Filter(apiResources, isMatch)
 }
 
-func findAPIResourcesFuzzy(apiResources []metav1.APIResource, identifier string) []metav1.APIResource {
-	isMatch := func(r metav1.APIResource, _ int) bool {
-		names := []string{
-			strings.ToLower(r.Name),
-			strings.ToLower(r.Kind),
-			strings.ToLower(r.Group),
-			strings.ToLower(r.SingularName),
-		}
-		names = append(names, r.Categories...)
-		names = append(names, r.ShortNames...)
-		return slices.Contains(names, strings.ToLower(identifier))
-	}
-
-	return lo.Filter(apiResources, isMatch)
-}
-
 type Resource struct {
 	Describe   string                 `json:"describe"`
 	Yaml       string                 `json:"yaml"`
 	References []relations.Reference  `json:"references"`
 	Object     map[string]interface{} `json:"object"`
-	Errors     []error                `json:"errors"`
+	// Managers of each field, most recent first
+	Owners map[string][]FieldOwner `json:"owners"`
+	Errors []error                 `json:"errors"`
 }
 
 func (kc *KubeCluster) GetResource(ctx context.Context, nsName string, group string, kind string, resourceName string) (*Resource, error) {
@@ -175,12 +234,18 @@ func (kc *KubeCluster) GetResource(ctx context.Context, nsName string, group str
 		return nil, fmt.Errorf("unable to GetKubeObject: %w", err)
 	}
 
+	// Before renderYaml removes managedFields
+	owners, err := FieldOwners(u)
+	if err != nil {
+		errors = append(errors, fmt.Errorf("unable to decode managedFields: %w", err))
+	}
+
 	yamlStr, err := renderYaml(u)
 	if err != nil {
 		errors = append(errors, fmt.Errorf("unable to serialize yaml: %w", err))
 	}
 
-	refs, err := relations.UnstructuredReferences(kc.scheme, u)
+	refs, err := kc.references(u)
 	if err != nil {
 		errors = append(errors, fmt.Errorf("unable to extract references: %w", err))
 	}
@@ -197,10 +262,20 @@ func (kc *KubeCluster) GetResource(ctx context.Context, nsName string, group str
 		Describe:   describeStr,
 		References: refs,
 		Object:     u.Object,
+		Owners:     owners,
 		Errors:     errors,
 	}, nil
 }
 
+// references from the scheme's types and the user's rules, or inferred when neither knows u's kind.
+func (kc *KubeCluster) references(u *unstructured.Unstructured) ([]relations.Reference, error) {
+	refs, err := relations.UnstructuredReferences(kc.scheme, kc.relationRules, u)
+	if !relations.HasRelationFunc(kc.relationRules, u.GroupVersionKind().GroupKind()) {
+		refs = append(refs, relations.InferredReferences(u, kc.apiResources)...)
+	}
+	return refs, err
+}
+
 func (kc *KubeCluster) getResource(ctx context.Context, r metav1.APIResource, namespace string, name string) (*unstructured.Unstructured, error) {
 	namespacable := kc.dynamicClient.Resource(toGVR(r))
 
@@ -256,12 +331,7 @@ func (kc *KubeCluster) Describe(ctx context.Context, nsName string, group string
 }
 
 func renderYaml(unstructured *unstructured.Unstructured) (string, error) {
-	// None of this managedFields nonsense
-	if untyped, ok := unstructured.Object["metadata"]; ok {
-		if md, ok := untyped.(map[string]interface{}); ok {
-			delete(md, "managedFields")
-		}
-	}
+	removeManagedFields(unstructured)
 
 	bs, err := yaml.Marshal(&unstructured.Object)
 	if err != nil {
@@ -271,6 +341,15 @@ func renderYaml(unstructured *unstructured.Unstructured) (string, error) {
 	return string(bs), nil
 }
 
+// None of this managedFields nonsense
+func removeManagedFields(unstructured *unstructured.Unstructured) {
+	if untyped, ok := unstructured.Object["metadata"]; ok {
+		if md, ok := untyped.(map[string]interface{}); ok {
+			delete(md, "managedFields")
+		}
+	}
+}
+
 func toGVR(r metav1.APIResource) schema.GroupVersionResource {
 	return schema.GroupVersionResource{
 		Group:    r.Group,
@@ -0,0 +1,167 @@
+package kube
+
+import (
+	"slices"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// How well an identifier names an APIResource. Lower is better.
+type matchRank int
+
+const (
+	matchExact matchRank = iota
+	matchShortName
+	matchPlural
+	matchGroup
+	matchPrefix
+	matchSubstring
+	matchTypo
+	noMatch
+)
+
+// findAPIResourcesFuzzy returns the resources identifier names best. Only the best rank is
+// returned so `pod` doesn't also list poddisruptionbudgets. The identifier may be qualified by
+// group, `deployments.apps`, or by version and group, `deployments.v1.apps`, to pick between
+// kinds with the same name.
+func findAPIResourcesFuzzy(apiResources []metav1.APIResource, identifier string) []metav1.APIResource {
+	name, group, version := splitQualified(strings.ToLower(strings.TrimSpace(identifier)))
+	if name == "" {
+		return []metav1.APIResource{}
+	}
+
+	best := noMatch
+	matches := make([]metav1.APIResource, 0)
+	for _, r := range apiResources {
+		if group != "" && !groupMatches(r, group, version) {
+			continue
+		}
+
+		rank := rankAPIResource(r, name)
+		switch {
+		case rank < best:
+			best = rank
+			matches = []metav1.APIResource{r}
+		case rank == best && rank != noMatch:
+			matches = append(matches, r)
+		}
+	}
+	return matches
+}
+
+// splitQualified splits `name.group` and `name.version.group`. Versions are recognized by their
+// `v<number>` prefix.
+func splitQualified(identifier string) (string, string, string) {
+	name, rest, found := strings.Cut(identifier, ".")
+	if !found {
+		return identifier, "", ""
+	}
+
+	version, group, found := strings.Cut(rest, ".")
+	if found && isVersion(version) {
+		return name, group, version
+	}
+	return name, rest, ""
+}
+
+func isVersion(s string) bool {
+	return len(s) > 1 && s[0] == 'v' && s[1] >= '0' && s[1] <= '9'
+}
+
+// groupMatches allows the group to be abbreviated at a dot, e.g. `certificates.cert-manager`.
+func groupMatches(r metav1.APIResource, group string, version string) bool {
+	if version != "" && r.Version != version {
+		return false
+	}
+	return r.Group == group || strings.HasPrefix(r.Group, group+".")
+}
+
+func rankAPIResource(r metav1.APIResource, identifier string) matchRank {
+	names := []string{
+		strings.ToLower(r.Name),
+		strings.ToLower(r.SingularName),
+		strings.ToLower(r.Kind),
+	}
+	shortNames := make([]string, len(r.ShortNames))
+	for i, sn := range r.ShortNames {
+		shortNames[i] = strings.ToLower(sn)
+	}
+
+	if slices.Contains(names, identifier) {
+		return matchExact
+	}
+	if slices.Contains(shortNames, identifier) {
+		return matchShortName
+	}
+	for _, form := range pluralForms(identifier) {
+		if slices.Contains(names, form) || slices.Contains(shortNames, form) {
+			return matchPlural
+		}
+	}
+	// Every resource in the group, e.g. `apps`
+	if strings.ToLower(r.Group) == identifier {
+		return matchGroup
+	}
+
+	rank := noMatch
+	for _, n := range names {
+		switch {
+		case strings.HasPrefix(n, identifier):
+			rank = min(rank, matchPrefix)
+		case strings.Contains(n, identifier):
+			rank = min(rank, matchSubstring)
+		case isTypo(n, identifier):
+			rank = min(rank, matchTypo)
+		}
+	}
+	return rank
+}
+
+// pluralForms are the identifier with a plural suffix added or removed, e.g. `deploys` for the
+// short name `deploy`.
+func pluralForms(identifier string) []string {
+	forms := []string{identifier + "s", identifier + "es"}
+	if s, found := strings.CutSuffix(identifier, "es"); found {
+		forms = append(forms, s)
+	}
+	if s, found := strings.CutSuffix(identifier, "s"); found {
+		forms = append(forms, s)
+	}
+	return forms
+}
+
+// isTypo allows one edit for short identifiers and two for longer ones. Very short identifiers
+// are too close to everything to guess at.
+func isTypo(name string, identifier string) bool {
+	if len(identifier) < 3 {
+		return false
+	}
+	allowed := 1
+	if len(identifier) > 5 {
+		allowed = 2
+	}
+	return editDistance(name, identifier) <= allowed
+}
+
+// editDistance is the Levenshtein distance between a and b.
+func editDistance(a string, b string) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
@@ -0,0 +1,63 @@
+package kube
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindAPIResourcesFuzzy(t *testing.T) {
+	ars := testAPIResources()
+
+	tests := []struct {
+		identifier string
+		expected   []string
+	}{
+		{"pods", []string{"pods"}},
+		{"Pod", []string{"pods"}},
+		{"po", []string{"pods"}},
+		{"svc", []string{"services"}},
+		{"deploy", []string{"deployments.apps"}},
+		{"deploys", []string{"deployments.apps"}},
+		{"deployment", []string{"deployments.apps", "deployments.example.com"}},
+		{"deployments.apps", []string{"deployments.apps"}},
+		{"deployments.v1alpha1.example.com", []string{"deployments.example.com"}},
+		{"deployments.v1.example.com", []string{}},
+		{"cert", []string{"certificates.cert-manager.io"}},
+		{"certificates.cert-manager", []string{"certificates.cert-manager.io"}},
+		{"csr", []string{"certificatesigningrequests.certificates.k8s.io"}},
+		{"apps", []string{"deployments.apps", "replicasets.apps"}},
+		{"ingr", []string{"ingresses.networking.k8s.io"}},
+		{"signing", []string{"certificatesigningrequests.certificates.k8s.io"}},
+		{"sevrices", []string{"services"}},
+		{"", []string{}},
+		{"nothing", []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.identifier, func(t *testing.T) {
+			actual := findAPIResourcesFuzzy(ars, tt.identifier)
+			assert.Equal(t, tt.expected, resourceNames(actual))
+		})
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	assert.Equal(t, 0, editDistance("pods", "pods"))
+	assert.Equal(t, 1, editDistance("pods", "pod"))
+	assert.Equal(t, 2, editDistance("services", "sevrices"))
+	assert.Equal(t, 3, editDistance("", "abc"))
+}
+
+func resourceNames(ars []metav1.APIResource) []string {
+	names := make([]string, len(ars))
+	for i, ar := range ars {
+		names[i] = ar.Name
+		if ar.Group != "" {
+			names[i] += "." + ar.Group
+		}
+	}
+	return names
+}
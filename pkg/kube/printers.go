@@ -8,16 +8,18 @@ import (
 	"bosun/pkg/kube/copyofk8sprinters/internalversion"
 
 	"github.com/samber/lo"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/duration"
 )
 
-func PrintList(scheme *runtime.Scheme, ar metav1.APIResource, uList *unstructured.UnstructuredList) (*metav1.Table, error) {
+// PrintList builds a table the way kubectl get does. Wide adds the columns with a Priority above 0.
+func PrintList(scheme *runtime.Scheme, ar metav1.APIResource, uList *unstructured.UnstructuredList, wide bool) (*metav1.Table, error) {
 	isRegistered := scheme.IsVersionRegistered(toGV(ar))
 	if isRegistered {
-		table, err := printRegistered(scheme, ar, uList)
+		table, err := printRegistered(scheme, ar, uList, wide)
 		if err != nil {
 			log.Info("printRegistered error, falling back to printUnstructured", "error", err)
 		} else {
@@ -36,7 +38,7 @@ func PrintError(err error) *metav1.Table {
 	}
 }
 
-func printRegistered(scheme *runtime.Scheme, ar metav1.APIResource, uList *unstructured.UnstructuredList) (*metav1.Table, error) {
+func printRegistered(scheme *runtime.Scheme, ar metav1.APIResource, uList *unstructured.UnstructuredList, wide bool) (*metav1.Table, error) {
 	gvk := uList.GetObjectKind().GroupVersionKind()
 
 	typedInstance, err := scheme.New(gvk)
@@ -50,14 +52,14 @@ func printRegistered(scheme *runtime.Scheme, ar metav1.APIResource, uList *unstr
 	}
 
 	tableGenerator := printers.NewTableGenerator().With(internalversion.AddHandlers)
-	table, err := tableGenerator.GenerateTable(typedInstance, printers.GenerateOptions{})
+	table, err := tableGenerator.GenerateTable(typedInstance, printers.GenerateOptions{Wide: wide})
 	if err != nil {
 		return nil, fmt.Errorf("unable to GenerateTable for %v: %w", gvk, err)
 	}
 
 	table.Rows = lo.Map(table.Rows, func(r metav1.TableRow, _ int) metav1.TableRow {
-		// This contains the entire Pod. Don't send it down to the client.
-		r.Object = runtime.RawExtension{}
+		// This contains the entire Pod. Don't send it down to the client, only the metadata.
+		r.Object = rowObject(r.Object.Object)
 		return r
 	})
 
@@ -76,6 +78,7 @@ func printUnstructured(uList *unstructured.UnstructuredList) (*metav1.Table, err
 				item.GetName(),
 				translateTimestampSince(item.GetCreationTimestamp()),
 			},
+			Object: rowObject(&item),
 		}
 	})
 
@@ -85,6 +88,41 @@ func printUnstructured(uList *unstructured.UnstructuredList) (*metav1.Table, err
 	}, nil
 }
 
+// rowObject is the metadata of obj, the same as the server returns for includeObject=Metadata.
+func rowObject(obj runtime.Object) runtime.RawExtension {
+	if obj == nil {
+		return runtime.RawExtension{}
+	}
+	m, err := meta.Accessor(obj)
+	if err != nil {
+		return runtime.RawExtension{}
+	}
+
+	return runtime.RawExtension{Object: &metav1.PartialObjectMetadata{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: metav1.SchemeGroupVersion.String(),
+			Kind:       "PartialObjectMetadata",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              m.GetName(),
+			Namespace:         m.GetNamespace(),
+			UID:               m.GetUID(),
+			Labels:            m.GetLabels(),
+			Annotations:       m.GetAnnotations(),
+			OwnerReferences:   m.GetOwnerReferences(),
+			CreationTimestamp: m.GetCreationTimestamp(),
+			ResourceVersion:   m.GetResourceVersion(),
+		},
+	}}
+}
+
+// RowMetadata returns the metadata of the object a row represents, or nil for rows without an
+// object such as errors.
+func RowMetadata(row metav1.TableRow) *metav1.PartialObjectMetadata {
+	pom, _ := row.Object.Object.(*metav1.PartialObjectMetadata)
+	return pom
+}
+
 // translateTimestampSince returns the elapsed time since timestamp in
 // human-readable approximation.
 func translateTimestampSince(timestamp metav1.Time) string {
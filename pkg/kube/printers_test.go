@@ -0,0 +1,101 @@
+package kube
+
+import (
+	"slices"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	runtime "k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrintListWide(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, schemeBuilder.AddToScheme(scheme))
+
+	ar := metav1.APIResource{Name: "pods", Kind: "Pod", Version: "v1", Namespaced: true}
+	uList := &unstructured.UnstructuredList{
+		Object: map[string]interface{}{"apiVersion": "v1", "kind": "PodList"},
+		Items: []unstructured.Unstructured{
+			{Object: map[string]interface{}{
+				"apiVersion": "v1",
+				"kind":       "Pod",
+				"metadata":   map[string]interface{}{"name": "web-0", "namespace": "shop"},
+				"spec":       map[string]interface{}{"nodeName": "node-a"},
+				"status":     map[string]interface{}{"podIP": "10.0.0.7"},
+			}},
+		},
+	}
+
+	table, err := PrintList(scheme, ar, uList, false)
+	assert.NoError(t, err)
+	assert.NotContains(t, columnNames(table), "Node")
+	for _, cd := range table.ColumnDefinitions {
+		assert.Zero(t, cd.Priority)
+	}
+
+	table, err = PrintList(scheme, ar, uList, true)
+	assert.NoError(t, err)
+	names := columnNames(table)
+	assert.Contains(t, names, "Node")
+	assert.Contains(t, names, "IP")
+	assert.Len(t, table.Rows[0].Cells, len(names))
+
+	node := table.ColumnDefinitions[slices.Index(names, "Node")]
+	assert.Equal(t, int32(1), node.Priority)
+	assert.Equal(t, "node-a", table.Rows[0].Cells[slices.Index(names, "Node")])
+}
+
+func TestRowMetadata(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, schemeBuilder.AddToScheme(scheme))
+
+	pod := map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Pod",
+		"metadata": map[string]interface{}{
+			"name":        "web-0",
+			"namespace":   "shop",
+			"uid":         "a1b2",
+			"labels":      map[string]interface{}{"app": "web"},
+			"annotations": map[string]interface{}{"note": "hi"},
+			"ownerReferences": []interface{}{
+				map[string]interface{}{"apiVersion": "apps/v1", "kind": "StatefulSet", "name": "web", "uid": "c3d4"},
+			},
+			"resourceVersion": "42",
+		},
+		"spec": map[string]interface{}{"nodeName": "node-a"},
+	}
+
+	registered := &unstructured.UnstructuredList{
+		Object: map[string]interface{}{"apiVersion": "v1", "kind": "PodList"},
+		Items:  []unstructured.Unstructured{{Object: pod}},
+	}
+	table, err := PrintList(scheme, metav1.APIResource{Name: "pods", Kind: "Pod", Version: "v1"}, registered, false)
+	assert.NoError(t, err)
+
+	// A CRD without a printer
+	crd := &unstructured.UnstructuredList{
+		Object: map[string]interface{}{"apiVersion": "example.com/v1", "kind": "WidgetList"},
+		Items:  []unstructured.Unstructured{{Object: pod}},
+	}
+	crdTable, err := PrintList(scheme, metav1.APIResource{Name: "widgets", Group: "example.com", Kind: "Widget", Version: "v1"}, crd, false)
+	assert.NoError(t, err)
+
+	for _, tbl := range []*metav1.Table{table, crdTable} {
+		m := RowMetadata(tbl.Rows[0])
+		if assert.NotNil(t, m) {
+			assert.Equal(t, "web-0", m.Name)
+			assert.Equal(t, "shop", m.Namespace)
+			assert.Equal(t, "a1b2", string(m.UID))
+			assert.Equal(t, map[string]string{"app": "web"}, m.Labels)
+			assert.Equal(t, map[string]string{"note": "hi"}, m.Annotations)
+			assert.Equal(t, "web", m.OwnerReferences[0].Name)
+			assert.Equal(t, "42", m.ResourceVersion)
+		}
+	}
+
+	assert.Nil(t, RowMetadata(PrintError(assert.AnError).Rows[0]))
+}
@@ -0,0 +1,71 @@
+package kube
+
+import (
+	"slices"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// QueryTerm is one comma separated part of a query: a kind, short name or category, and
+// optionally the name of a single object, `deploy/web`.
+type QueryTerm struct {
+	Identifier string `json:"identifier"`
+	Name       string `json:"name"`
+}
+
+// ParseQuery splits `deploy,svc,ing/web` into terms, in order. Empty terms are dropped.
+func ParseQuery(query string) []QueryTerm {
+	terms := make([]QueryTerm, 0)
+	for _, part := range strings.Split(query, ",") {
+		identifier, name, _ := strings.Cut(strings.TrimSpace(part), "/")
+		identifier = strings.TrimSpace(identifier)
+		if identifier == "" {
+			continue
+		}
+		terms = append(terms, QueryTerm{Identifier: identifier, Name: strings.TrimSpace(name)})
+	}
+	return terms
+}
+
+// resolveIdentifier expands a category, e.g. `all`, to the resources in it, like kubectl does
+// before it looks for a kind. Anything else is a fuzzy kind.
+func resolveIdentifier(apiResources []metav1.APIResource, identifier string) []metav1.APIResource {
+	category := strings.ToLower(strings.TrimSpace(identifier))
+	inCategory := make([]metav1.APIResource, 0)
+	for _, r := range apiResources {
+		if slices.ContainsFunc(r.Categories, func(c string) bool { return strings.ToLower(c) == category }) {
+			inCategory = append(inCategory, r)
+		}
+	}
+	if len(inCategory) > 0 {
+		return inCategory
+	}
+	return findAPIResourcesFuzzy(apiResources, identifier)
+}
+
+// queryTarget is a resource to list for a term. Name limits the list to one object.
+type queryTarget struct {
+	apiResource metav1.APIResource
+	name        string
+}
+
+// resolveQuery maps each term to resources, keeping the order of the terms. A resource named by
+// more than one term, e.g. `all,po`, is listed once where it first appears.
+func resolveQuery(apiResources []metav1.APIResource, terms []QueryTerm) [][]queryTarget {
+	seen := map[string]bool{}
+	resolved := make([][]queryTarget, 0, len(terms))
+	for _, term := range terms {
+		targets := make([]queryTarget, 0)
+		for _, ar := range resolveIdentifier(apiResources, term.Identifier) {
+			key := toGVR(ar).String() + "/" + term.Name
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			targets = append(targets, queryTarget{apiResource: ar, name: term.Name})
+		}
+		resolved = append(resolved, targets)
+	}
+	return resolved
+}
@@ -0,0 +1,44 @@
+package kube
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseQuery(t *testing.T) {
+	assert.Equal(t, []QueryTerm{{Identifier: "all"}}, ParseQuery("all"))
+	assert.Equal(t, []QueryTerm{
+		{Identifier: "deploy"},
+		{Identifier: "svc"},
+		{Identifier: "ing", Name: "web"},
+	}, ParseQuery(" deploy, svc,,ing/web "))
+	assert.Equal(t, []QueryTerm{}, ParseQuery(""))
+}
+
+func TestResolveQuery(t *testing.T) {
+	resolved := resolveQuery(testAPIResources(), ParseQuery("svc,all,deploy/web"))
+
+	names := make([][]string, len(resolved))
+	for i, targets := range resolved {
+		for _, t := range targets {
+			names[i] = append(names[i], t.apiResource.Name+"/"+t.name)
+		}
+	}
+
+	assert.Equal(t, [][]string{
+		{"services/"},
+		// services was already requested
+		{"pods/", "deployments/", "replicasets/"},
+		{"deployments/web"},
+	}, names)
+}
+
+func TestResolveIdentifier(t *testing.T) {
+	ars := testAPIResources()
+
+	assert.Equal(t, []string{"pods", "services", "deployments.apps", "replicasets.apps"}, resourceNames(resolveIdentifier(ars, "all")))
+	// A category is expanded before it's matched as a kind
+	assert.Equal(t, []string{"certificates.cert-manager.io"}, resourceNames(resolveIdentifier(ars, "Cert-Manager")))
+	assert.Equal(t, []string{"services"}, resourceNames(resolveIdentifier(ars, "svc")))
+}
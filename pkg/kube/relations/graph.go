@@ -0,0 +1,160 @@
+package relations
+
+import (
+	"fmt"
+	"strings"
+)
+
+type Node struct {
+	Id        string `json:"id"`
+	Group     string `json:"group"`
+	Version   string `json:"version"`
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	// The object couldn't be fetched so its references are unknown
+	Missing bool `json:"missing"`
+}
+
+type Edge struct {
+	From         string       `json:"from"`
+	To           string       `json:"to"`
+	RelationType RelationType `json:"relationType"`
+	Property     string       `json:"property"`
+	Inferred     bool         `json:"inferred"`
+}
+
+// Graph of objects connected by their References. Dot and Mermaid are rendered by Render.
+type Graph struct {
+	Nodes   []*Node `json:"nodes"`
+	Edges   []Edge  `json:"edges"`
+	Dot     string  `json:"dot"`
+	Mermaid string  `json:"mermaid"`
+	// The walk stopped at its limit so objects and references are missing
+	Truncated bool `json:"truncated"`
+
+	byId map[string]*Node
+}
+
+func MakeGraph() *Graph {
+	return &Graph{
+		Nodes: make([]*Node, 0),
+		Edges: make([]Edge, 0),
+		byId:  map[string]*Node{},
+	}
+}
+
+func NodeId(group string, kind string, namespace string, name string) string {
+	return fmt.Sprintf("%s/%s/%s/%s", group, kind, namespace, name)
+}
+
+// AddNode returns the existing node with the same identity or adds a new one.
+func (g *Graph) AddNode(group string, version string, kind string, namespace string, name string) (*Node, bool) {
+	id := NodeId(group, kind, namespace, name)
+	if n, found := g.byId[id]; found {
+		return n, false
+	}
+
+	n := &Node{
+		Id:        id,
+		Group:     group,
+		Version:   version,
+		Kind:      kind,
+		Name:      name,
+		Namespace: namespace,
+	}
+	g.Nodes = append(g.Nodes, n)
+	g.byId[id] = n
+	return n, true
+}
+
+func (g *Graph) AddEdge(from *Node, to *Node, ref Reference) {
+	g.Edges = append(g.Edges, Edge{
+		From:         from.Id,
+		To:           to.Id,
+		RelationType: ref.RelationType,
+		Property:     ref.Property,
+		Inferred:     ref.Inferred,
+	})
+}
+
+// Render fills in the Dot and Mermaid text.
+func (g *Graph) Render() {
+	g.Dot = g.renderDot()
+	g.Mermaid = g.renderMermaid()
+}
+
+func (g *Graph) renderDot() string {
+	var b strings.Builder
+	b.WriteString("digraph G {\n")
+	b.WriteString("  rankdir=LR;\n")
+	b.WriteString("  node [shape=box];\n")
+	if g.Truncated {
+		fmt.Fprintf(&b, "  label=%s;\n", dotQuote(g.truncatedLabel()))
+	}
+	for _, n := range g.Nodes {
+		attrs := ""
+		if n.Missing {
+			attrs = ", style=dashed"
+		}
+		fmt.Fprintf(&b, "  %s [label=%s%s];\n", dotQuote(n.Id), dotQuote(n.label("\n")), attrs)
+	}
+	for _, e := range g.Edges {
+		attrs := ""
+		if e.Inferred {
+			attrs = ", style=dashed"
+		}
+		fmt.Fprintf(&b, "  %s -> %s [label=%s%s];\n", dotQuote(e.From), dotQuote(e.To), dotQuote(e.Property), attrs)
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+func (g *Graph) renderMermaid() string {
+	// Mermaid ids can't contain most punctuation so number the nodes.
+	ids := make(map[string]string, len(g.Nodes))
+	var b strings.Builder
+	b.WriteString("graph LR\n")
+	for i, n := range g.Nodes {
+		ids[n.Id] = fmt.Sprintf("n%d", i)
+		fmt.Fprintf(&b, "  %s[\"%s\"]\n", ids[n.Id], mermaidEscape(n.label("<br/>")))
+	}
+	for _, e := range g.Edges {
+		arrow := "-->"
+		if e.Inferred {
+			arrow = "-.->"
+		}
+		if e.Property == "" {
+			fmt.Fprintf(&b, "  %s %s %s\n", ids[e.From], arrow, ids[e.To])
+		} else {
+			fmt.Fprintf(&b, "  %s %s|\"%s\"| %s\n", ids[e.From], arrow, mermaidEscape(e.Property), ids[e.To])
+		}
+	}
+	if g.Truncated {
+		fmt.Fprintf(&b, "  truncated>\"%s\"]\n", mermaidEscape(g.truncatedLabel()))
+	}
+	return b.String()
+}
+
+func (g *Graph) truncatedLabel() string {
+	return fmt.Sprintf("Truncated at %d objects", len(g.Nodes))
+}
+
+func (n *Node) label(sep string) string {
+	name := n.Name
+	if n.Namespace != "" {
+		name = n.Namespace + "/" + name
+	}
+	return n.Kind + sep + name
+}
+
+func dotQuote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	s = strings.ReplaceAll(s, "\n", `\n`)
+	return `"` + s + `"`
+}
+
+func mermaidEscape(s string) string {
+	return strings.ReplaceAll(s, `"`, "#quot;")
+}
@@ -0,0 +1,56 @@
+package relations
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGraph(t *testing.T) {
+	g := MakeGraph()
+	pod, added := g.AddNode("", "v1", "Pod", "shop", "web-0")
+	assert.True(t, added)
+	node, _ := g.AddNode("", "v1", "Node", "", "worker-1")
+	secret, _ := g.AddNode("", "v1", "Secret", "shop", `web "tls"`)
+
+	again, added := g.AddNode("", "v1", "Pod", "shop", "web-0")
+	assert.False(t, added)
+	assert.Same(t, pod, again)
+
+	g.AddEdge(pod, node, Reference{RelationType: HasOne, Property: ".spec.nodeName"})
+	g.AddEdge(pod, secret, Reference{RelationType: HasOne, Property: ".spec.volumes[0].secret.secretName", Inferred: true})
+	g.Render()
+
+	assert.Len(t, g.Nodes, 3)
+	assert.Len(t, g.Edges, 2)
+	assert.Equal(t, "/Pod/shop/web-0", g.Edges[0].From)
+
+	assert.Equal(t, `digraph G {
+  rankdir=LR;
+  node [shape=box];
+  "/Pod/shop/web-0" [label="Pod\nshop/web-0"];
+  "/Node//worker-1" [label="Node\nworker-1"];
+  "/Secret/shop/web \"tls\"" [label="Secret\nshop/web \"tls\""];
+  "/Pod/shop/web-0" -> "/Node//worker-1" [label=".spec.nodeName"];
+  "/Pod/shop/web-0" -> "/Secret/shop/web \"tls\"" [label=".spec.volumes[0].secret.secretName", style=dashed];
+}
+`, g.Dot)
+
+	assert.Equal(t, `graph LR
+  n0["Pod<br/>shop/web-0"]
+  n1["Node<br/>worker-1"]
+  n2["Secret<br/>shop/web #quot;tls#quot;"]
+  n0 -->|".spec.nodeName"| n1
+  n0 -.->|".spec.volumes[0].secret.secretName"| n2
+`, g.Mermaid)
+}
+
+func TestGraphTruncated(t *testing.T) {
+	g := MakeGraph()
+	g.AddNode("", "v1", "Pod", "shop", "web-0")
+	g.Truncated = true
+	g.Render()
+
+	assert.Contains(t, g.Dot, `  label="Truncated at 1 objects";`)
+	assert.Contains(t, g.Mermaid, `  truncated>"Truncated at 1 objects"]`)
+}
@@ -51,9 +51,11 @@ type Reference struct {
 	Name      string
 	Namespace string
 	Property  string
+	// Guessed from the shape of the object rather than known from its type
+	Inferred bool
 }
 
-func UnstructuredReferences(s *runtime.Scheme, u *unstructured.Unstructured) ([]Reference, error) {
+func UnstructuredReferences(s *runtime.Scheme, rules []Rule, u *unstructured.Unstructured) ([]Reference, error) {
 	refs := MetaReferences(u)
 
 	gk := u.GetObjectKind().GroupVersionKind().GroupKind()
@@ -61,6 +63,18 @@ func UnstructuredReferences(s *runtime.Scheme, u *unstructured.Unstructured) ([]
 		return nil, fmt.Errorf("no kind for %v", u)
 	}
 
+	ruleRefs, err := RuleReferences(rules, u)
+	if err != nil {
+		return nil, fmt.Errorf("unable to evaluate relation rules: %w", err)
+	}
+	refs = append(refs, ruleRefs...)
+
+	// Only types in the scheme have refFuncs. Check before s.New so CRDs don't fail here.
+	f := refFuncs[gk]
+	if f == nil {
+		return refs, nil
+	}
+
 	into, err := s.New(u.GetObjectKind().GroupVersionKind())
 	if err != nil {
 		return nil, fmt.Errorf("unable to intantiate unstructured: %w", err)
@@ -70,11 +84,6 @@ func UnstructuredReferences(s *runtime.Scheme, u *unstructured.Unstructured) ([]
 		return nil, fmt.Errorf("unable to convert FromUnstructured: %w", err)
 	}
 
-	f := refFuncs[gk]
-	if f == nil {
-		return refs, nil
-	}
-
 	// Call the function for this GroupKind
 	v := reflect.ValueOf(f)
 	rVals := v.Call([]reflect.Value{reflect.ValueOf(into)})
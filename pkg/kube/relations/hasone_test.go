@@ -41,7 +41,7 @@ func TestPodReferences(t *testing.T) {
 	un := &unstructured.Unstructured{Object: obj}
 	assert.NotEmpty(t, un)
 
-	refs, err := UnstructuredReferences(s, un)
+	refs, err := UnstructuredReferences(s, nil, un)
 	assert.NoError(t, err)
 	assert.NotEmpty(t, refs)
 
@@ -0,0 +1,213 @@
+package relations
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// Well known property names that hold the name of an object of a specific kind.
+var namedKinds = map[string]string{
+	"secretName":         "Secret",
+	"configMapName":      "ConfigMap",
+	"serviceAccountName": "ServiceAccount",
+	"claimName":          "PersistentVolumeClaim",
+	"nodeName":           "Node",
+	"storageClassName":   "StorageClass",
+	"priorityClassName":  "PriorityClass",
+	"serviceName":        "Service",
+}
+
+// HasRelationFunc is true when references for gk come from a refFunc or a user's rule rather than
+// inference.
+func HasRelationFunc(rules []Rule, gk schema.GroupKind) bool {
+	if refFuncs[gk] != nil {
+		return true
+	}
+	for _, r := range rules {
+		if r.GroupKind() == gk {
+			return true
+		}
+	}
+	return false
+}
+
+// InferredReferences walks u looking for common reference shapes and keeps the ones that resolve
+// to a kind in apiResources. It's a guess so every Reference is marked Inferred.
+//
+//   - fooRef / fooReference: {name, kind?, apiVersion?, namespace?}
+//   - secretName, configMapName, serviceAccountName, ...
+//   - any ObjectReference like struct: {kind, name, apiVersion?, namespace?}
+//
+// metadata is skipped since MetaReferences already covers it.
+func InferredReferences(u *unstructured.Unstructured, apiResources []metav1.APIResource) []Reference {
+	w := inferWalker{
+		namespace:    u.GetNamespace(),
+		apiResources: apiResources,
+		refs:         make([]Reference, 0),
+	}
+
+	keys := sortedKeys(u.Object)
+	for _, k := range keys {
+		if k == "metadata" || k == "apiVersion" || k == "kind" {
+			continue
+		}
+		w.walk("."+k, k, u.Object[k])
+	}
+
+	return w.refs
+}
+
+type inferWalker struct {
+	namespace    string
+	apiResources []metav1.APIResource
+	refs         []Reference
+}
+
+func (w *inferWalker) walk(path string, key string, v interface{}) {
+	switch typed := v.(type) {
+	case map[string]interface{}:
+		if w.objectReference(path, key, typed) {
+			return
+		}
+		for _, k := range sortedKeys(typed) {
+			w.walk(path+"."+k, k, typed[k])
+		}
+	case []interface{}:
+		for i, item := range typed {
+			w.walk(fmt.Sprintf("%s[%d]", path, i), key, item)
+		}
+	case string:
+		if kind, ok := namedKinds[key]; ok && typed != "" {
+			w.add(path, "*", kind, typed, "")
+		}
+	}
+}
+
+// objectReference adds a Reference when m looks like one and reports whether it did.
+func (w *inferWalker) objectReference(path string, key string, m map[string]interface{}) bool {
+	name, _ := m["name"].(string)
+	if name == "" {
+		return false
+	}
+
+	kinds := []string{}
+	if kind, ok := m["kind"].(string); ok && kind != "" {
+		kinds = append(kinds, kind)
+	} else {
+		// secretRef -> Secret, configMapKeyRef -> ConfigMap, passwordSecretRef -> Secret
+		kinds = camelSuffixes(refKeyKind(key))
+	}
+	if len(kinds) == 0 {
+		return false
+	}
+
+	group := ""
+	groupKnown := false
+	if apiVersion, ok := m["apiVersion"].(string); ok && apiVersion != "" {
+		gv, err := schema.ParseGroupVersion(apiVersion)
+		if err == nil {
+			group, groupKnown = gv.Group, true
+		}
+	}
+	for _, groupKey := range []string{"apiGroup", "group"} {
+		if g, ok := m[groupKey].(string); ok && !groupKnown {
+			group, groupKnown = g, true
+		}
+	}
+
+	ns, _ := m["namespace"].(string)
+	if !groupKnown {
+		group = "*"
+	}
+	for _, kind := range kinds {
+		if w.add(path+".name", group, kind, name, ns) {
+			return true
+		}
+	}
+	return false
+}
+
+func (w *inferWalker) add(path string, group string, kind string, name string, namespace string) bool {
+	ar, ok := resolveKind(w.apiResources, group, kind)
+	if !ok {
+		return false
+	}
+
+	if ar.Namespaced && namespace == "" {
+		namespace = w.namespace
+	}
+	if !ar.Namespaced {
+		namespace = ""
+	}
+
+	w.refs = append(w.refs, Reference{
+		RelationType: HasOne,
+		Group:        ar.Group,
+		Version:      ar.Version,
+		Kind:         ar.Kind,
+		Name:         name,
+		Namespace:    namespace,
+		Property:     path,
+		Inferred:     true,
+	})
+	return true
+}
+
+// resolveKind finds kind in apiResources. A group of "*" matches any group, preferring core.
+func resolveKind(apiResources []metav1.APIResource, group string, kind string) (metav1.APIResource, bool) {
+	var found *metav1.APIResource
+	for i, ar := range apiResources {
+		if !strings.EqualFold(ar.Kind, kind) {
+			continue
+		}
+		if group != "*" && ar.Group != group {
+			continue
+		}
+		if found == nil || (found.Group != "" && ar.Group == "") {
+			found = &apiResources[i]
+		}
+	}
+
+	if found == nil {
+		return metav1.APIResource{}, false
+	}
+	return *found, true
+}
+
+func refKeyKind(key string) string {
+	for _, suffix := range []string{"KeyRef", "Reference", "Ref"} {
+		if strings.HasSuffix(key, suffix) {
+			return strings.TrimSuffix(key, suffix)
+		}
+	}
+	return ""
+}
+
+// camelSuffixes returns s and each shorter camel case suffix, e.g. passwordSecret, Secret.
+func camelSuffixes(s string) []string {
+	if s == "" {
+		return nil
+	}
+	suffixes := []string{s}
+	for i := 1; i < len(s); i++ {
+		if unicode.IsUpper(rune(s[i])) {
+			suffixes = append(suffixes, s[i:])
+		}
+	}
+	return suffixes
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,90 @@
+package relations
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testAPIResources() []metav1.APIResource {
+	return []metav1.APIResource{
+		{Group: "", Version: "v1", Kind: "Secret", Namespaced: true},
+		{Group: "", Version: "v1", Kind: "ConfigMap", Namespaced: true},
+		{Group: "", Version: "v1", Kind: "ServiceAccount", Namespaced: true},
+		{Group: "storage.k8s.io", Version: "v1", Kind: "StorageClass", Namespaced: false},
+		{Group: "cert-manager.io", Version: "v1", Kind: "Issuer", Namespaced: true},
+		{Group: "cert-manager.io", Version: "v1", Kind: "ClusterIssuer", Namespaced: false},
+		{Group: "acme.example.com", Version: "v1", Kind: "Issuer", Namespaced: true},
+	}
+}
+
+func TestInferredReferences(t *testing.T) {
+	u := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "cert-manager.io/v1",
+		"kind":       "Certificate",
+		"metadata": map[string]interface{}{
+			"name":      "web",
+			"namespace": "shop",
+			"ownerReferences": []interface{}{
+				map[string]interface{}{"kind": "Secret", "name": "ignored"},
+			},
+		},
+		"spec": map[string]interface{}{
+			"secretName": "web-tls",
+			"issuerRef": map[string]interface{}{
+				"group": "cert-manager.io",
+				"kind":  "ClusterIssuer",
+				"name":  "letsencrypt",
+			},
+			"keystores": map[string]interface{}{
+				"pkcs12": map[string]interface{}{
+					"passwordSecretRef": map[string]interface{}{"name": "web-pkcs12", "key": "password"},
+				},
+			},
+			"storageClassName": "fast",
+			"containers": []interface{}{
+				map[string]interface{}{
+					"name": "not-a-reference",
+					"env": []interface{}{
+						map[string]interface{}{
+							"valueFrom": map[string]interface{}{
+								"configMapKeyRef": map[string]interface{}{"name": "settings", "key": "level"},
+							},
+						},
+					},
+				},
+			},
+		},
+	}}
+
+	refs := InferredReferences(u, testAPIResources())
+
+	assert.Equal(t, []Reference{
+		{RelationType: HasOne, Version: "v1", Kind: "ConfigMap", Name: "settings", Namespace: "shop",
+			Property: ".spec.containers[0].env[0].valueFrom.configMapKeyRef.name", Inferred: true},
+		{RelationType: HasOne, Group: "cert-manager.io", Version: "v1", Kind: "ClusterIssuer", Name: "letsencrypt",
+			Property: ".spec.issuerRef.name", Inferred: true},
+		{RelationType: HasOne, Version: "v1", Kind: "Secret", Name: "web-pkcs12", Namespace: "shop",
+			Property: ".spec.keystores.pkcs12.passwordSecretRef.name", Inferred: true},
+		{RelationType: HasOne, Version: "v1", Kind: "Secret", Name: "web-tls", Namespace: "shop",
+			Property: ".spec.secretName", Inferred: true},
+		{RelationType: HasOne, Group: "storage.k8s.io", Version: "v1", Kind: "StorageClass", Name: "fast",
+			Property: ".spec.storageClassName", Inferred: true},
+	}, refs)
+}
+
+func TestResolveKind(t *testing.T) {
+	ar, ok := resolveKind(testAPIResources(), "*", "issuer")
+	assert.True(t, ok)
+	assert.Equal(t, "cert-manager.io", ar.Group)
+
+	ar, ok = resolveKind(testAPIResources(), "acme.example.com", "Issuer")
+	assert.True(t, ok)
+	assert.Equal(t, "acme.example.com", ar.Group)
+
+	_, ok = resolveKind(testAPIResources(), "*", "Container")
+	assert.False(t, ok)
+}
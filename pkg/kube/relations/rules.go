@@ -0,0 +1,144 @@
+package relations
+
+import (
+	"fmt"
+	"strings"
+
+	"bosun/pkg/util"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// RuleFile is the on disk format for user defined relation rules.
+type RuleFile struct {
+	Version int    `yaml:"version"`
+	Rules   []Rule `yaml:"rules"`
+}
+
+// Rule declares a reference from every object of Group, Kind to the object named at NamePath.
+// Unlike refFuncs these are evaluated directly on the unstructured object so they work for CRDs.
+type Rule struct {
+	Group string `yaml:"group"`
+	Kind  string `yaml:"kind"`
+	// JSONPath to the name of the target, e.g. .spec.credentials.secretName
+	NamePath string `yaml:"namePath"`
+	// Optional JSONPath to the namespace of the target. Defaults to the object's namespace.
+	NamespacePath string     `yaml:"namespacePath,omitempty"`
+	Target        RuleTarget `yaml:"target"`
+	// HAS_ONE if empty
+	RelationType string `yaml:"relationType,omitempty"`
+}
+
+type RuleTarget struct {
+	Group   string `yaml:"group"`
+	Version string `yaml:"version"`
+	Kind    string `yaml:"kind"`
+}
+
+func ParseRelationType(s string) (RelationType, error) {
+	if s == "" {
+		return HasOne, nil
+	}
+	for _, rt := range AllRelationTypes {
+		if strings.EqualFold(rt.String(), s) {
+			return rt, nil
+		}
+	}
+	return HasOne, fmt.Errorf("unknown relation type %s", s)
+}
+
+func (r Rule) GroupKind() schema.GroupKind {
+	return schema.GroupKind{Group: r.Group, Kind: r.Kind}
+}
+
+func (r Rule) Validate() error {
+	if r.Kind == "" {
+		return fmt.Errorf("rule is missing kind")
+	}
+	if r.Target.Kind == "" || r.Target.Version == "" {
+		return fmt.Errorf("rule for %s is missing target version or kind", r.GroupKind())
+	}
+	if _, err := ParseRelationType(r.RelationType); err != nil {
+		return fmt.Errorf("rule for %s: %w", r.GroupKind(), err)
+	}
+	if _, err := util.ParseJSONPath(r.NamePath); err != nil {
+		return fmt.Errorf("rule for %s has invalid namePath: %w", r.GroupKind(), err)
+	}
+	if r.NamespacePath != "" {
+		if _, err := util.ParseJSONPath(r.NamespacePath); err != nil {
+			return fmt.Errorf("rule for %s has invalid namespacePath: %w", r.GroupKind(), err)
+		}
+	}
+	return nil
+}
+
+// RuleReferences evaluates every rule matching u's GroupKind. A NamePath that matches several
+// values, e.g. .spec.backends[*].secretName, produces a Reference for each.
+func RuleReferences(rules []Rule, u *unstructured.Unstructured) ([]Reference, error) {
+	refs := make([]Reference, 0)
+	gk := u.GetObjectKind().GroupVersionKind().GroupKind()
+
+	for _, r := range rules {
+		if r.GroupKind() != gk {
+			continue
+		}
+
+		relationType, err := ParseRelationType(r.RelationType)
+		if err != nil {
+			return nil, err
+		}
+
+		names, err := evalPath(r.NamePath, u)
+		if err != nil {
+			return nil, fmt.Errorf("unable to evaluate namePath for %s: %w", gk, err)
+		}
+
+		namespace := u.GetNamespace()
+		if r.NamespacePath != "" {
+			nss, err := evalPath(r.NamespacePath, u)
+			if err != nil {
+				return nil, fmt.Errorf("unable to evaluate namespacePath for %s: %w", gk, err)
+			}
+			if len(nss) > 0 {
+				namespace = nss[0]
+			}
+		}
+
+		for _, name := range names {
+			refs = append(refs, Reference{
+				RelationType: relationType,
+				Group:        r.Target.Group,
+				Version:      r.Target.Version,
+				Kind:         r.Target.Kind,
+				Name:         name,
+				Namespace:    namespace,
+				Property:     r.NamePath,
+			})
+		}
+	}
+
+	return refs, nil
+}
+
+func evalPath(path string, u *unstructured.Unstructured) ([]string, error) {
+	jp, err := util.ParseJSONPath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	results, err := jp.FindResults(u.Object)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make([]string, 0)
+	for _, rs := range results {
+		for _, r := range rs {
+			if s, ok := r.Interface().(string); ok && s != "" {
+				values = append(values, s)
+			}
+		}
+	}
+	return values, nil
+}
@@ -0,0 +1,109 @@
+package relations
+
+import (
+	"testing"
+
+	"bosun/pkg/util"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/kubernetes/scheme"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func databaseCR() *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "platform.example.com/v1",
+		"kind":       "Database",
+		"metadata": map[string]interface{}{
+			"name":      "orders",
+			"namespace": "shop",
+		},
+		"spec": map[string]interface{}{
+			"credentialsSecret": "orders-db-creds",
+			"replicas": []interface{}{
+				map[string]interface{}{"service": "orders-db-0"},
+				map[string]interface{}{"service": "orders-db-1"},
+			},
+		},
+	}}
+}
+
+func databaseRules() []Rule {
+	return []Rule{
+		{
+			Group:    "platform.example.com",
+			Kind:     "Database",
+			NamePath: ".spec.credentialsSecret",
+			Target:   RuleTarget{Version: "v1", Kind: "Secret"},
+		},
+		{
+			Group:    "platform.example.com",
+			Kind:     "Database",
+			NamePath: "{.spec.replicas[*].service}",
+			Target:   RuleTarget{Version: "v1", Kind: "Service"},
+		},
+		{
+			Group:    "other.example.com",
+			Kind:     "Database",
+			NamePath: ".spec.credentialsSecret",
+			Target:   RuleTarget{Version: "v1", Kind: "ConfigMap"},
+		},
+	}
+}
+
+func TestRuleReferences(t *testing.T) {
+	refs, err := RuleReferences(databaseRules(), databaseCR())
+	assert.NoError(t, err)
+	assert.Len(t, refs, 3)
+
+	assert.Equal(t, Reference{
+		RelationType: HasOne,
+		Version:      "v1",
+		Kind:         "Secret",
+		Name:         "orders-db-creds",
+		Namespace:    "shop",
+		Property:     ".spec.credentialsSecret",
+	}, refs[0])
+	assert.Equal(t, "orders-db-0", refs[1].Name)
+	assert.Equal(t, "orders-db-1", refs[2].Name)
+}
+
+func TestRuleReferencesMissingField(t *testing.T) {
+	u := databaseCR()
+	unstructured.RemoveNestedField(u.Object, "spec", "credentialsSecret")
+
+	refs, err := RuleReferences(databaseRules()[:1], u)
+	assert.NoError(t, err)
+	assert.Empty(t, refs)
+}
+
+func TestUnstructuredReferencesNotInScheme(t *testing.T) {
+	refs, err := UnstructuredReferences(scheme.Scheme, databaseRules(), databaseCR())
+	assert.NoError(t, err)
+
+	// Namespace + 3 from rules
+	assert.Len(t, refs, 4)
+	assert.Equal(t, "Namespace", refs[0].Kind)
+}
+
+func TestRuleValidate(t *testing.T) {
+	valid, errs := util.Valid([]Rule{
+		databaseRules()[0],
+		{Kind: "Database", NamePath: ".spec.name"},
+		{Kind: "Database", NamePath: ".spec[", Target: RuleTarget{Version: "v1", Kind: "Secret"}},
+		{Kind: "Database", NamePath: ".spec.name", Target: RuleTarget{Version: "v1", Kind: "Secret"}, RelationType: "NOPE"},
+	})
+	assert.Len(t, valid, 1)
+	assert.Len(t, errs, 3)
+}
+
+func TestParseRelationType(t *testing.T) {
+	rt, err := ParseRelationType("")
+	assert.NoError(t, err)
+	assert.Equal(t, HasOne, rt)
+
+	rt, err = ParseRelationType("attribute_search")
+	assert.NoError(t, err)
+	assert.Equal(t, AttributeSearch, rt)
+}
@@ -0,0 +1,275 @@
+package kube
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/samber/lo"
+	"gopkg.in/yaml.v3"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// Ways to render an object. Full is everything but managedFields. Clean is what belongs in git: no
+// status, no server generated metadata and no fields equal to their default. Defaults are only
+// known for a fixed list of built in kinds, so the defaults of CRDs and their schemas are kept.
+// Spec is only the spec, or for kinds without one, like ConfigMap, everything but metadata and
+// status.
+const (
+	RenderFull  = "full"
+	RenderClean = "clean"
+	RenderSpec  = "spec"
+	RenderJSON  = "json"
+)
+
+// fieldDefault is a field the api server sets to value when it's missing. Segments ending in []
+// are lists of objects. Only defaults that are the same on every cluster are listed. Ones that
+// depend on its configuration or feature gates, like a Service's ipFamilies or a pod's priority,
+// are kept.
+type fieldDefault struct {
+	path  string
+	value interface{}
+}
+
+var podSpecDefaults = []fieldDefault{
+	{"restartPolicy", "Always"},
+	{"terminationGracePeriodSeconds", int64(30)},
+	{"dnsPolicy", "ClusterFirst"},
+	{"schedulerName", "default-scheduler"},
+	{"securityContext", map[string]interface{}{}},
+	{"enableServiceLinks", true},
+	{"preemptionPolicy", "PreemptLowerPriority"},
+}
+
+var containerDefaults = []fieldDefault{
+	{"terminationMessagePath", "/dev/termination-log"},
+	{"terminationMessagePolicy", "File"},
+	{"resources", map[string]interface{}{}},
+	{"ports[].protocol", "TCP"},
+	{"livenessProbe.timeoutSeconds", int64(1)},
+	{"livenessProbe.periodSeconds", int64(10)},
+	{"livenessProbe.successThreshold", int64(1)},
+	{"livenessProbe.failureThreshold", int64(3)},
+	{"readinessProbe.timeoutSeconds", int64(1)},
+	{"readinessProbe.periodSeconds", int64(10)},
+	{"readinessProbe.successThreshold", int64(1)},
+	{"readinessProbe.failureThreshold", int64(3)},
+	{"startupProbe.timeoutSeconds", int64(1)},
+	{"startupProbe.periodSeconds", int64(10)},
+	{"startupProbe.successThreshold", int64(1)},
+	{"startupProbe.failureThreshold", int64(3)},
+}
+
+// Defaults of the built in kinds, outside of their pod spec
+var kindDefaults = map[schema.GroupKind][]fieldDefault{
+	{Group: "apps", Kind: "Deployment"}: {
+		{"spec.revisionHistoryLimit", int64(10)},
+		{"spec.progressDeadlineSeconds", int64(600)},
+		{"spec.strategy.rollingUpdate.maxSurge", "25%"},
+		{"spec.strategy.rollingUpdate.maxUnavailable", "25%"},
+		{"spec.strategy.type", "RollingUpdate"},
+	},
+	{Group: "apps", Kind: "StatefulSet"}: {
+		{"spec.revisionHistoryLimit", int64(10)},
+		{"spec.podManagementPolicy", "OrderedReady"},
+		{"spec.updateStrategy.rollingUpdate.partition", int64(0)},
+		{"spec.updateStrategy.type", "RollingUpdate"},
+		{"spec.persistentVolumeClaimRetentionPolicy.whenDeleted", "Retain"},
+		{"spec.persistentVolumeClaimRetentionPolicy.whenScaled", "Retain"},
+	},
+	{Group: "apps", Kind: "DaemonSet"}: {
+		{"spec.revisionHistoryLimit", int64(10)},
+		{"spec.updateStrategy.rollingUpdate.maxSurge", int64(0)},
+		{"spec.updateStrategy.rollingUpdate.maxUnavailable", int64(1)},
+		{"spec.updateStrategy.type", "RollingUpdate"},
+	},
+	{Group: "batch", Kind: "Job"}: {
+		{"spec.backoffLimit", int64(6)},
+		{"spec.completionMode", "NonIndexed"},
+		{"spec.suspend", false},
+	},
+	{Group: "batch", Kind: "CronJob"}: {
+		{"spec.concurrencyPolicy", "Allow"},
+		{"spec.suspend", false},
+		{"spec.successfulJobsHistoryLimit", int64(3)},
+		{"spec.failedJobsHistoryLimit", int64(1)},
+		{"spec.jobTemplate.spec.backoffLimit", int64(6)},
+		{"spec.jobTemplate.spec.completionMode", "NonIndexed"},
+		{"spec.jobTemplate.spec.suspend", false},
+	},
+	{Kind: "Service"}: {
+		{"spec.type", "ClusterIP"},
+		{"spec.sessionAffinity", "None"},
+		{"spec.internalTrafficPolicy", "Cluster"},
+		{"spec.ports[].protocol", "TCP"},
+	},
+	{Kind: "PersistentVolumeClaim"}: {
+		{"spec.volumeMode", "Filesystem"},
+	},
+}
+
+// Where the pod spec is in each kind that has one
+var podSpecPaths = map[schema.GroupKind]string{
+	{Kind: "Pod"}:                        "spec",
+	{Kind: "ReplicationController"}:      "spec.template.spec",
+	{Group: "apps", Kind: "Deployment"}:  "spec.template.spec",
+	{Group: "apps", Kind: "StatefulSet"}: "spec.template.spec",
+	{Group: "apps", Kind: "DaemonSet"}:   "spec.template.spec",
+	{Group: "apps", Kind: "ReplicaSet"}:  "spec.template.spec",
+	{Group: "batch", Kind: "Job"}:        "spec.template.spec",
+	{Group: "batch", Kind: "CronJob"}:    "spec.jobTemplate.spec.template.spec",
+}
+
+// RenderObject renders a copy of obj in mode.
+func RenderObject(obj map[string]interface{}, mode string) (string, error) {
+	u := &unstructured.Unstructured{Object: obj}
+	u = u.DeepCopy()
+
+	switch mode {
+	case RenderFull, "":
+		return renderYaml(u)
+
+	case RenderClean:
+		cleanDefaults(u)
+		return renderYaml(u)
+
+	case RenderSpec:
+		cleanDefaults(u)
+		spec, ok := u.Object["spec"]
+		if !ok {
+			spec = lo.OmitByKeys(u.Object, []string{"apiVersion", "kind", "metadata", "status"})
+		}
+		bs, err := yaml.Marshal(spec)
+		if err != nil {
+			return "", fmt.Errorf("unable to marshal spec: %w", err)
+		}
+		return string(bs), nil
+
+	case RenderJSON:
+		removeManagedFields(u)
+		bs, err := json.MarshalIndent(u.Object, "", "  ")
+		if err != nil {
+			return "", fmt.Errorf("unable to marshal json: %w", err)
+		}
+		return string(bs) + "\n", nil
+	}
+	return "", fmt.Errorf("unknown render mode %s", mode)
+}
+
+// cleanDefaults removes what CleanObject does, but keeps the namespace, then the fields that equal
+// the default the api server would set.
+func cleanDefaults(u *unstructured.Unstructured) {
+	ns := u.GetNamespace()
+	CleanObject(u)
+	if ns != "" {
+		u.SetNamespace(ns)
+	}
+
+	gk := u.GroupVersionKind().GroupKind()
+	for _, d := range kindDefaults[gk] {
+		removeDefault(u.Object, strings.Split(d.path, "."), d.value)
+	}
+
+	if gk.Kind == "Service" {
+		// targetPort defaults to port
+		ports, _, _ := unstructured.NestedSlice(u.Object, "spec", "ports")
+		for _, p := range ports {
+			if port, ok := p.(map[string]interface{}); ok && jsonEqual(port["port"], port["targetPort"]) {
+				delete(port, "targetPort")
+			}
+		}
+		if len(ports) > 0 {
+			_ = unstructured.SetNestedSlice(u.Object, ports, "spec", "ports")
+		}
+	}
+
+	podSpecPath, ok := podSpecPaths[gk]
+	if !ok {
+		return
+	}
+	podSpecFields := strings.Split(podSpecPath, ".")
+	if len(podSpecFields) > 1 {
+		// The template's metadata, not the object's
+		templateMetadata := append(podSpecFields[:len(podSpecFields)-1:len(podSpecFields)-1], "metadata", "creationTimestamp")
+		removeDefault(u.Object, templateMetadata, nil)
+	}
+
+	podSpec, found, _ := unstructured.NestedMap(u.Object, podSpecFields...)
+	if !found {
+		return
+	}
+	for _, d := range podSpecDefaults {
+		removeDefault(podSpec, strings.Split(d.path, "."), d.value)
+	}
+	if podSpec["serviceAccount"] == podSpec["serviceAccountName"] {
+		// Deprecated copy of serviceAccountName
+		delete(podSpec, "serviceAccount")
+	}
+	for _, containers := range []string{"containers", "initContainers"} {
+		list, _ := podSpec[containers].([]interface{})
+		for _, c := range list {
+			container, ok := c.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			for _, d := range containerDefaults {
+				removeDefault(container, strings.Split(d.path, "."), d.value)
+			}
+			if image, _ := container["image"].(string); container["imagePullPolicy"] == defaultPullPolicy(image) {
+				delete(container, "imagePullPolicy")
+			}
+		}
+	}
+	_ = unstructured.SetNestedMap(u.Object, podSpec, podSpecFields...)
+}
+
+// defaultPullPolicy is Always for the latest tag and IfNotPresent otherwise.
+func defaultPullPolicy(image string) string {
+	if strings.Contains(image, "@") {
+		return "IfNotPresent"
+	}
+	name := image[strings.LastIndex(image, "/")+1:]
+	_, tag, tagged := strings.Cut(name, ":")
+	if !tagged || tag == "latest" {
+		return "Always"
+	}
+	return "IfNotPresent"
+}
+
+// removeDefault deletes the field at path when it equals value. Objects from the frontend were
+// decoded from JSON, so numbers compare by value rather than type. Objects emptied by the removal
+// are removed too.
+func removeDefault(m map[string]interface{}, path []string, value interface{}) {
+	field, isList := strings.CutSuffix(path[0], "[]")
+	child, ok := m[field]
+	if !ok {
+		return
+	}
+
+	if len(path) == 1 {
+		if jsonEqual(child, value) {
+			delete(m, field)
+		}
+		return
+	}
+
+	if isList {
+		list, _ := child.([]interface{})
+		for _, item := range list {
+			if itemMap, ok := item.(map[string]interface{}); ok {
+				removeDefault(itemMap, path[1:], value)
+			}
+		}
+		return
+	}
+
+	childMap, ok := child.(map[string]interface{})
+	if !ok || len(childMap) == 0 {
+		return
+	}
+	removeDefault(childMap, path[1:], value)
+	if len(childMap) == 0 {
+		delete(m, field)
+	}
+}
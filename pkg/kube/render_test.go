@@ -0,0 +1,223 @@
+package kube
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testDeployment() map[string]interface{} {
+	return map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata": map[string]interface{}{
+			"name":            "web",
+			"namespace":       "shop",
+			"uid":             "a1b2",
+			"resourceVersion": "42",
+			"generation":      int64(3),
+			"labels":          map[string]interface{}{"app": "web"},
+			"annotations": map[string]interface{}{
+				"deployment.kubernetes.io/revision": "3",
+			},
+			"managedFields": []interface{}{map[string]interface{}{"manager": "kubectl"}},
+		},
+		"spec": map[string]interface{}{
+			"replicas":                int64(2),
+			"revisionHistoryLimit":    int64(10),
+			"progressDeadlineSeconds": int64(600),
+			"strategy": map[string]interface{}{
+				"type":          "RollingUpdate",
+				"rollingUpdate": map[string]interface{}{"maxSurge": "25%", "maxUnavailable": "25%"},
+			},
+			"template": map[string]interface{}{
+				"metadata": map[string]interface{}{
+					"creationTimestamp": nil,
+					"labels":            map[string]interface{}{"app": "web"},
+				},
+				"spec": map[string]interface{}{
+					"restartPolicy":                 "Always",
+					"dnsPolicy":                     "ClusterFirst",
+					"schedulerName":                 "default-scheduler",
+					"securityContext":               map[string]interface{}{},
+					"terminationGracePeriodSeconds": int64(60),
+					"containers": []interface{}{
+						map[string]interface{}{
+							"name":                     "web",
+							"image":                    "nginx:1.27",
+							"imagePullPolicy":          "IfNotPresent",
+							"terminationMessagePath":   "/dev/termination-log",
+							"terminationMessagePolicy": "File",
+							"resources":                map[string]interface{}{},
+							"ports": []interface{}{
+								map[string]interface{}{"containerPort": int64(80), "protocol": "TCP"},
+							},
+						},
+						map[string]interface{}{
+							"name":            "sidecar",
+							"image":           "example.com:5000/sidecar",
+							"imagePullPolicy": "IfNotPresent",
+						},
+					},
+				},
+			},
+		},
+		"status": map[string]interface{}{"replicas": int64(2)},
+	}
+}
+
+func TestRenderObjectClean(t *testing.T) {
+	obj := testDeployment()
+	clean, err := RenderObject(obj, RenderClean)
+	assert.NoError(t, err)
+	assert.Equal(t, `apiVersion: apps/v1
+kind: Deployment
+metadata:
+    labels:
+        app: web
+    name: web
+    namespace: shop
+spec:
+    replicas: 2
+    template:
+        metadata:
+            labels:
+                app: web
+        spec:
+            containers:
+                - image: nginx:1.27
+                  name: web
+                  ports:
+                    - containerPort: 80
+                - image: example.com:5000/sidecar
+                  imagePullPolicy: IfNotPresent
+                  name: sidecar
+            terminationGracePeriodSeconds: 60
+`, clean)
+
+	// The object isn't changed
+	assert.Contains(t, obj, "status")
+	assert.Equal(t, int64(10), obj["spec"].(map[string]interface{})["revisionHistoryLimit"])
+}
+
+func TestRenderObjectCleanFromJSON(t *testing.T) {
+	// Objects from the frontend have float64 numbers
+	bs, err := json.Marshal(testDeployment())
+	assert.NoError(t, err)
+	var obj map[string]interface{}
+	assert.NoError(t, json.Unmarshal(bs, &obj))
+
+	fromJSON, err := RenderObject(obj, RenderClean)
+	assert.NoError(t, err)
+	clean, err := RenderObject(testDeployment(), RenderClean)
+	assert.NoError(t, err)
+	assert.Equal(t, clean, fromJSON)
+	assert.NotContains(t, fromJSON, "revisionHistoryLimit")
+}
+
+func TestRenderObjectModes(t *testing.T) {
+	full, err := RenderObject(testDeployment(), RenderFull)
+	assert.NoError(t, err)
+	assert.Contains(t, full, "revisionHistoryLimit: 10")
+	assert.Contains(t, full, "status:")
+	assert.NotContains(t, full, "managedFields")
+
+	spec, err := RenderObject(testDeployment(), RenderSpec)
+	assert.NoError(t, err)
+	assert.Contains(t, spec, "replicas: 2\n")
+	assert.NotContains(t, spec, "kind:")
+	assert.NotContains(t, spec, "progressDeadlineSeconds")
+
+	configMap := map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata":   map[string]interface{}{"name": "settings"},
+		"data":       map[string]interface{}{"a": "1"},
+	}
+	spec, err = RenderObject(configMap, RenderSpec)
+	assert.NoError(t, err)
+	assert.Equal(t, "data:\n    a: \"1\"\n", spec)
+
+	js, err := RenderObject(configMap, RenderJSON)
+	assert.NoError(t, err)
+	assert.Equal(t, `{
+  "apiVersion": "v1",
+  "data": {
+    "a": "1"
+  },
+  "kind": "ConfigMap",
+  "metadata": {
+    "name": "settings"
+  }
+}
+`, js)
+
+	_, err = RenderObject(configMap, "xml")
+	assert.Error(t, err)
+}
+
+func TestRenderObjectCleanService(t *testing.T) {
+	svc := map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Service",
+		"metadata":   map[string]interface{}{"name": "web", "namespace": "shop"},
+		"spec": map[string]interface{}{
+			"type":            "ClusterIP",
+			"clusterIP":       "10.0.0.1",
+			"clusterIPs":      []interface{}{"10.0.0.1"},
+			"ipFamilies":      []interface{}{"IPv4"},
+			"ipFamilyPolicy":  "SingleStack",
+			"sessionAffinity": "None",
+			"selector":        map[string]interface{}{"app": "web"},
+			"ports": []interface{}{
+				map[string]interface{}{"port": int64(80), "targetPort": int64(80), "protocol": "TCP"},
+				map[string]interface{}{"port": int64(443), "targetPort": "https", "protocol": "TCP"},
+			},
+		},
+	}
+	clean, err := RenderObject(svc, RenderClean)
+	assert.NoError(t, err)
+	assert.Equal(t, `apiVersion: v1
+kind: Service
+metadata:
+    name: web
+    namespace: shop
+spec:
+    ipFamilies:
+        - IPv4
+    ipFamilyPolicy: SingleStack
+    ports:
+        - port: 80
+        - port: 443
+          targetPort: https
+    selector:
+        app: web
+`, clean)
+}
+
+func TestRenderObjectCleanDualStackService(t *testing.T) {
+	svc := map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Service",
+		"metadata":   map[string]interface{}{"name": "web", "namespace": "shop"},
+		"spec": map[string]interface{}{
+			"type":           "ClusterIP",
+			"ipFamilies":     []interface{}{"IPv6", "IPv4"},
+			"ipFamilyPolicy": "PreferDualStack",
+			"ports":          []interface{}{map[string]interface{}{"port": int64(80), "protocol": "TCP"}},
+		},
+	}
+	clean, err := RenderObject(svc, RenderClean)
+	assert.NoError(t, err)
+	assert.Contains(t, clean, "ipFamilyPolicy: PreferDualStack\n")
+	assert.Contains(t, clean, "ipFamilies:\n        - IPv6\n        - IPv4\n")
+}
+
+func TestDefaultPullPolicy(t *testing.T) {
+	assert.Equal(t, "Always", defaultPullPolicy("nginx"))
+	assert.Equal(t, "Always", defaultPullPolicy("nginx:latest"))
+	assert.Equal(t, "Always", defaultPullPolicy("example.com:5000/nginx"))
+	assert.Equal(t, "IfNotPresent", defaultPullPolicy("example.com:5000/nginx:1.27"))
+	assert.Equal(t, "IfNotPresent", defaultPullPolicy("nginx@sha256:abc"))
+}
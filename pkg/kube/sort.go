@@ -0,0 +1,270 @@
+package kube
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Display values that mean there's nothing to sort by
+var noValues = []string{"", COLUMN_NONE, "<unknown>", "<pending>"}
+
+// Restart counts are displayed like "3 (2m ago)"
+var countPattern = regexp.MustCompile(`^(\d+)( \(.*\))?$`)
+
+// Durations are displayed like "5d3h" by duration.HumanDuration
+var durationPattern = regexp.MustCompile(`^(\d+[smhdy])+$`)
+var durationPartPattern = regexp.MustCompile(`(\d+)([smhdy])`)
+
+// How a column's sort values were parsed
+const (
+	ColumnString   = "string"
+	ColumnNumber   = "number"
+	ColumnDuration = "duration"
+	ColumnQuantity = "quantity"
+)
+
+// sortValues returns a raw value for every cell: a float64 or a string, or nil when the cell is
+// empty, and the type of each column. Each column is typed as a whole so "250m" is a quantity in a
+// CPU column and a duration in an age column. Age is the seconds since the creationTimestamp in
+// each row's metadata, so it sorts the same as the displayed value.
+func sortValues(table *metav1.Table) ([][]interface{}, []string) {
+	now := time.Now()
+	values := make([][]interface{}, len(table.Rows))
+	for i := range table.Rows {
+		values[i] = make([]interface{}, len(table.ColumnDefinitions))
+	}
+	types := make([]string, len(table.ColumnDefinitions))
+
+	for col, cd := range table.ColumnDefinitions {
+		if strings.EqualFold(cd.Name, "age") && hasRowMetadata(table) {
+			for i, row := range table.Rows {
+				if ts := RowMetadata(row).CreationTimestamp; !ts.IsZero() {
+					values[i][col] = now.Sub(ts.Time).Seconds()
+				}
+			}
+			types[col] = ColumnDuration
+			continue
+		}
+
+		cells := make([]interface{}, len(table.Rows))
+		for i, row := range table.Rows {
+			if col < len(row.Cells) {
+				cells[i] = row.Cells[col]
+			}
+		}
+		columnType, parsed := columnSortValues(cells)
+		for i, v := range parsed {
+			values[i][col] = v
+		}
+		types[col] = columnType
+	}
+	return values, types
+}
+
+func hasRowMetadata(table *metav1.Table) bool {
+	for _, row := range table.Rows {
+		if RowMetadata(row) == nil {
+			return false
+		}
+	}
+	return true
+}
+
+// columnSortValues uses the first parser that accepts every non-empty cell in the column.
+func columnSortValues(cells []interface{}) (string, []interface{}) {
+	parsers := []struct {
+		columnType string
+		parse      func(interface{}) (interface{}, bool)
+	}{
+		{ColumnNumber, parseNumber},
+		{ColumnNumber, parseCount},
+		{ColumnDuration, parseDuration},
+		{ColumnQuantity, parseQuantity},
+	}
+
+	for _, p := range parsers {
+		if parsed, ok := parseColumn(cells, p.parse); ok {
+			return p.columnType, parsed
+		}
+	}
+
+	parsed, _ := parseColumn(cells, func(c interface{}) (interface{}, bool) {
+		return fmt.Sprint(c), true
+	})
+	return ColumnString, parsed
+}
+
+func parseColumn(cells []interface{}, parse func(interface{}) (interface{}, bool)) ([]interface{}, bool) {
+	parsed := make([]interface{}, len(cells))
+	for i, c := range cells {
+		if isNoValue(c) {
+			continue
+		}
+		v, ok := parse(c)
+		if !ok {
+			return nil, false
+		}
+		parsed[i] = v
+	}
+	return parsed, true
+}
+
+func isNoValue(c interface{}) bool {
+	if c == nil {
+		return true
+	}
+	s, isString := c.(string)
+	if !isString {
+		return false
+	}
+	for _, nv := range noValues {
+		if s == nv {
+			return true
+		}
+	}
+	return false
+}
+
+func parseNumber(c interface{}) (interface{}, bool) {
+	switch v := c.(type) {
+	case int:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case float64:
+		return v, true
+	}
+	return nil, false
+}
+
+func parseCount(c interface{}) (interface{}, bool) {
+	s, ok := c.(string)
+	if !ok {
+		return nil, false
+	}
+	m := countPattern.FindStringSubmatch(s)
+	if m == nil {
+		return nil, false
+	}
+	n, err := strconv.ParseFloat(m[1], 64)
+	return n, err == nil
+}
+
+// parseDuration is the inverse of duration.HumanDuration, in seconds.
+func parseDuration(c interface{}) (interface{}, bool) {
+	s, ok := c.(string)
+	if !ok || !durationPattern.MatchString(s) {
+		return nil, false
+	}
+
+	units := map[string]time.Duration{
+		"s": time.Second,
+		"m": time.Minute,
+		"h": time.Hour,
+		"d": 24 * time.Hour,
+		"y": 365 * 24 * time.Hour,
+	}
+	var d time.Duration
+	for _, part := range durationPartPattern.FindAllStringSubmatch(s, -1) {
+		n, err := strconv.Atoi(part[1])
+		if err != nil {
+			return nil, false
+		}
+		d += time.Duration(n) * units[part[2]]
+	}
+	return d.Seconds(), true
+}
+
+func parseQuantity(c interface{}) (interface{}, bool) {
+	s, ok := c.(string)
+	if !ok {
+		return nil, false
+	}
+	q, err := resource.ParseQuantity(s)
+	if err != nil {
+		return nil, false
+	}
+	return q.AsApproximateFloat64(), true
+}
+
+// SortResourceTable orders the rows of rt by column, matched case insensitively. Empty values are
+// last in either direction. It reports whether rt has the column.
+func SortResourceTable(rt *ResourceTable, column string, descending bool) bool {
+	if rt.Table == nil {
+		return false
+	}
+	col := findColumn(rt.Table, column)
+	if col == -1 {
+		return false
+	}
+
+	order := make([]int, len(rt.Table.Rows))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		a := rt.SortValues[order[i]][col]
+		b := rt.SortValues[order[j]][col]
+		if a == nil || b == nil {
+			return a != nil
+		}
+		if descending {
+			return compareSortValues(b, a) < 0
+		}
+		return compareSortValues(a, b) < 0
+	})
+
+	selectRows(rt, order)
+	return true
+}
+
+// selectRows replaces the rows of rt with the rows at indexes, in that order.
+func selectRows(rt *ResourceTable, indexes []int) {
+	rows := make([]metav1.TableRow, len(indexes))
+	values := make([][]interface{}, len(indexes))
+	names := make([]string, len(indexes))
+	for i, idx := range indexes {
+		rows[i] = rt.Table.Rows[idx]
+		values[i] = rt.SortValues[idx]
+		names[i] = rt.TableRowNames[idx]
+	}
+	rt.Table.Rows = rows
+	rt.SortValues = values
+	rt.TableRowNames = names
+}
+
+// findColumn matches column case insensitively, with underscores for spaces, e.g. nominated_node.
+func findColumn(table *metav1.Table, column string) int {
+	column = strings.ReplaceAll(column, "_", " ")
+	for i, cd := range table.ColumnDefinitions {
+		if strings.EqualFold(cd.Name, column) {
+			return i
+		}
+	}
+	return -1
+}
+
+func compareSortValues(a interface{}, b interface{}) int {
+	switch av := a.(type) {
+	case float64:
+		if bv, ok := b.(float64); ok {
+			switch {
+			case av < bv:
+				return -1
+			case av > bv:
+				return 1
+			}
+			return 0
+		}
+	}
+	return strings.Compare(fmt.Sprint(a), fmt.Sprint(b))
+}
@@ -0,0 +1,90 @@
+package kube
+
+import (
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	runtime "k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testSortTable() *metav1.Table {
+	now := time.Now()
+	table := &metav1.Table{
+		ColumnDefinitions: []metav1.TableColumnDefinition{
+			{Name: "Name"}, {Name: "Restarts"}, {Name: "CPU"}, {Name: "Last Seen"}, {Name: "Age"}, {Name: "Port"},
+		},
+		Rows: []metav1.TableRow{
+			{Cells: []interface{}{"web-0", "3 (2m ago)", "250m", "5d3h", "5d", int64(8080)}},
+			{Cells: []interface{}{"web-1", "12 (1h ago)", "1", "45s", "45s", int64(80)}},
+			{Cells: []interface{}{"web-2", "0", "<none>", "2y", "<unknown>", int64(443)}},
+		},
+	}
+
+	for i, age := range []time.Duration{5 * 24 * time.Hour, 45 * time.Second, 0} {
+		u := &unstructured.Unstructured{Object: map[string]interface{}{}}
+		u.SetName(table.Rows[i].Cells[0].(string))
+		if age > 0 {
+			u.SetCreationTimestamp(metav1.NewTime(now.Add(-age)))
+		}
+		table.Rows[i].Object = rowObject(u)
+	}
+	return table
+}
+
+func TestSortValues(t *testing.T) {
+	table := testSortTable()
+	values, types := sortValues(table)
+	assert.Equal(t, []string{ColumnString, ColumnNumber, ColumnQuantity, ColumnDuration, ColumnDuration, ColumnNumber}, types)
+
+	assert.Equal(t, "web-0", values[0][0])
+	assert.Equal(t, 3.0, values[0][1])
+	assert.Equal(t, 12.0, values[1][1])
+	assert.Equal(t, 0.25, values[0][2])
+	assert.Nil(t, values[2][2])
+	assert.Equal(t, float64(5*24*3600+3*3600), values[0][3])
+	assert.InDelta(t, float64(5*24*3600), values[0][4], 60)
+	assert.Nil(t, values[2][4])
+	assert.Equal(t, 8080.0, values[0][5])
+
+	// Without metadata, age is parsed from the display value
+	for i := range table.Rows {
+		table.Rows[i].Object = runtime.RawExtension{}
+	}
+	values, types = sortValues(table)
+	assert.Equal(t, ColumnDuration, types[4])
+	assert.Equal(t, float64(45), values[1][4])
+}
+
+func TestSortResourceTable(t *testing.T) {
+	table := testSortTable()
+	values, types := sortValues(table)
+	rt := &ResourceTable{
+		Table:         table,
+		TableRowNames: []string{"web-0", "web-1", "web-2"},
+		SortValues:    values,
+		ColumnTypes:   types,
+	}
+
+	assert.True(t, SortResourceTable(rt, "restarts", true))
+	assert.Equal(t, []string{"web-1", "web-0", "web-2"}, rt.TableRowNames)
+	assert.Equal(t, "web-1", rt.Table.Rows[0].Cells[0])
+
+	// Empty values stay last in both directions
+	assert.True(t, SortResourceTable(rt, "CPU", false))
+	assert.Equal(t, []string{"web-0", "web-1", "web-2"}, rt.TableRowNames)
+	assert.True(t, SortResourceTable(rt, "CPU", true))
+	assert.Equal(t, []string{"web-1", "web-0", "web-2"}, rt.TableRowNames)
+
+	// Youngest first, like the displayed ages
+	assert.True(t, SortResourceTable(rt, "Age", false))
+	assert.Equal(t, []string{"web-1", "web-0", "web-2"}, rt.TableRowNames)
+
+	assert.True(t, SortResourceTable(rt, "Port", false))
+	assert.Equal(t, []string{"web-1", "web-2", "web-0"}, rt.TableRowNames)
+
+	assert.False(t, SortResourceTable(rt, "Nope", false))
+}
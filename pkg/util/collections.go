@@ -14,3 +14,17 @@ func Partition[A any](as []A, f func(A) bool) ([]A, []A) {
 
 	return yes, no
 }
+
+// Valid drops the items that fail validation and returns an error for each.
+func Valid[A interface{ Validate() error }](as []A) ([]A, []error) {
+	valid := make([]A, 0, len(as))
+	var errs []error
+	for _, a := range as {
+		if err := a.Validate(); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		valid = append(valid, a)
+	}
+	return valid, errs
+}
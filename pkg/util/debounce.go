@@ -0,0 +1,61 @@
+package util
+
+import (
+	"sync"
+	"time"
+)
+
+// Debouncer runs f once, after calls to Trigger have stopped for the wait duration.
+type Debouncer struct {
+	lock    sync.Mutex
+	wait    time.Duration
+	f       func()
+	timer   *time.Timer
+	pending bool
+	// Held while f runs so Flush waits for a run the timer started
+	running sync.Mutex
+}
+
+func NewDebouncer(wait time.Duration, f func()) *Debouncer {
+	return &Debouncer{
+		wait: wait,
+		f:    f,
+	}
+}
+
+func (d *Debouncer) Trigger() {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	d.pending = true
+	if d.timer != nil {
+		d.timer.Stop()
+	}
+	d.timer = time.AfterFunc(d.wait, d.run)
+}
+
+// Flush runs f now if a Trigger is waiting, e.g. before shutdown. A run already in progress
+// finishes first so it can't overwrite what Flush writes.
+func (d *Debouncer) Flush() {
+	d.lock.Lock()
+	if d.timer != nil {
+		d.timer.Stop()
+	}
+	d.lock.Unlock()
+
+	d.run()
+}
+
+func (d *Debouncer) run() {
+	d.running.Lock()
+	defer d.running.Unlock()
+
+	d.lock.Lock()
+	pending := d.pending
+	d.pending = false
+	d.lock.Unlock()
+
+	if pending {
+		d.f()
+	}
+}
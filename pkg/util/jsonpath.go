@@ -0,0 +1,25 @@
+package util
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/client-go/util/jsonpath"
+)
+
+// ParseJSONPath accepts both kubectl's relaxed .spec.name and the template form {.spec.name}.
+// Missing keys find nothing rather than fail.
+func ParseJSONPath(path string) (*jsonpath.JSONPath, error) {
+	if path == "" {
+		return nil, fmt.Errorf("empty path")
+	}
+	if !strings.HasPrefix(path, "{") {
+		path = fmt.Sprintf("{%s}", path)
+	}
+
+	jp := jsonpath.New(path).AllowMissingKeys(true)
+	if err := jp.Parse(path); err != nil {
+		return nil, err
+	}
+	return jp, nil
+}